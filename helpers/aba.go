@@ -0,0 +1,125 @@
+// An ABA routing transit number is composed by a 4-digit Federal Reserve routing symbol,
+// a 4-digit ABA institution identifier and a check digit, such that
+// 3(d1+d4+d7) + 7(d2+d5+d8) + (d3+d6+d9) is a multiple of 10. The first two digits
+// identify the Federal Reserve district: 00-12, 21-32, 61-72 or 80.
+//
+// A US bank account number has 4 to 17 digits, and its leading zeros are significant.
+package helper
+
+import (
+	"errors"
+)
+
+const (
+	routingNumberLen    = 9
+	routingSymbolLen    = 4
+	accountNumberMinLen = 4
+	accountNumberMaxLen = 17
+)
+
+// ABARouting is the 3-7-1 weighted check digit of ABA routing numbers.
+var ABARouting CheckDigit = trailingCheck{1, abaRoutingCheck}
+
+var (
+	// ErrInvalidRoutingNumber is returned when a routing number does not have 9 digits
+	// separated by spaces or dashes, or does not start with a valid Federal Reserve
+	// district.
+	ErrInvalidRoutingNumber = errors.New("helper: invalid ABA routing number")
+	// ErrInvalidAccountNumber is returned when a bank account number does not have 4 to 17
+	// digits separated by spaces or dashes.
+	ErrInvalidAccountNumber = errors.New("helper: invalid bank account number")
+)
+
+// RoutingNumberOptions configures the processors of a RoutingNumberField.
+type RoutingNumberOptions struct {
+	// KeepRoutingSymbol leaves the 4-digit Federal Reserve routing symbol in clear.
+	KeepRoutingSymbol bool
+}
+
+// routingNumberCodec enciphers ABA routing numbers into valid routing numbers, leaving the
+// separators in place. Routing numbers with an invalid check digit are rejected with
+// ErrInvalidCheckDigit.
+type routingNumberCodec struct {
+	opts RoutingNumberOptions
+}
+
+func (c routingNumberCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var runes = []rune(in)
+	var numeralString = make([]uint16, 0, routingNumberLen)
+	for _, r := range runes {
+		switch {
+		case r >= '0' && r <= '9':
+			if len(numeralString) == routingNumberLen {
+				return "", ErrInvalidRoutingNumber
+			}
+			numeralString = append(numeralString, uint16(r-'0'))
+		case r != ' ' && r != '-':
+			return "", ErrInvalidRoutingNumber
+		}
+	}
+	if len(numeralString) != routingNumberLen || !validRoutingDistrict(numeralString) {
+		return "", ErrInvalidRoutingNumber
+	}
+
+	var keep, valid = 0, validRoutingDistrict
+	if c.opts.KeepRoutingSymbol {
+		keep, valid = routingSymbolLen, nil
+	}
+	if err := cryptCheckDigit(newMode, ABARouting, runes, keep, valid); err != nil {
+		return "", err
+	}
+	return string(runes), nil
+}
+
+// validRoutingDistrict tells if a numeral string starts with a Federal Reserve district
+// of a routing number: 00 for the US government, 01-12, 21-32 for thrift institutions,
+// 61-72 for electronic transactions and 80 for traveler's checks.
+func validRoutingDistrict(numeralString []uint16) bool {
+	var district = numeralString[0]*10 + numeralString[1]
+	return district <= 12 || (district >= 21 && district <= 32) || (district >= 61 && district <= 72) || district == 80
+}
+
+func abaRoutingCheck(payload string) (string, error) {
+	var numeralString, err = decimalNumeralString(payload)
+	if err != nil {
+		return "", err
+	}
+
+	var weights = []uint16{3, 7, 1}
+	var sum = uint16(0)
+	for i, num := range numeralString {
+		sum += weights[i%3] * num
+	}
+	return string(rune('0' + (10-sum%10)%10)), nil
+}
+
+// accountNumberCodec enciphers all the digits of bank account numbers, keeping their
+// length, leading zeros included, and the separators.
+type accountNumberCodec struct{}
+
+func (c accountNumberCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var runes = []rune(in)
+	var numeralString = make([]uint16, 0, accountNumberMaxLen)
+
+	// Create numeral string
+	for _, r := range runes {
+		switch {
+		case r >= '0' && r <= '9':
+			if len(numeralString) == accountNumberMaxLen {
+				return "", ErrInvalidAccountNumber
+			}
+			numeralString = append(numeralString, uint16(r-'0'))
+		case r != ' ' && r != '-':
+			return "", ErrInvalidAccountNumber
+		}
+	}
+	if len(numeralString) < accountNumberMinLen {
+		return "", ErrInvalidAccountNumber
+	}
+
+	if err := cryptDigits(newMode, runes, 0, len(numeralString), nil); err != nil {
+		return "", err
+	}
+
+	return string(runes), nil
+}
@@ -0,0 +1,113 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+)
+
+var routingNumbers = []string{"011000015", "021000021", "0260-0959-3", "322271627", "111000025", "063 100 277"}
+
+func TestRoutingNumberEncryptDecrypt(t *testing.T) {
+	for _, a := range configAlgorithms {
+		for _, keep := range []bool{false, true} {
+			var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: RoutingNumberField,
+				RoutingNumber: RoutingNumberOptions{KeepRoutingSymbol: keep}})
+			if err != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+			}
+
+			for _, rtn := range routingNumbers {
+				var enc, errEnc = p.Encrypt(rtn)
+				if errEnc != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, rtn, errEnc)
+					continue
+				}
+
+				var compact, encCompact = strings.NewReplacer(" ", "", "-", "").Replace(rtn), strings.NewReplacer(" ", "", "-", "").Replace(enc)
+				var numeralString, _ = decimalNumeralString(encCompact)
+				if len(enc) != len(rtn) || len(numeralString) != routingNumberLen || !ABARouting.Validate(encCompact) || !validRoutingDistrict(numeralString) {
+					t.Errorf("%s(%s): %s is not a valid routing number with the format of %s", t.Name(), a.alg, enc, rtn)
+					continue
+				}
+				if keep && encCompact[:routingSymbolLen] != compact[:routingSymbolLen] {
+					t.Errorf("%s(%s): %s does not keep the routing symbol of %s", t.Name(), a.alg, enc, rtn)
+				}
+
+				var dec, errDec = p.Decrypt(enc)
+				if errDec != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, rtn, errDec)
+					continue
+				}
+				if strings.Compare(dec, rtn) != 0 {
+					t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, rtn)
+				}
+			}
+		}
+	}
+}
+
+func TestRoutingNumberErrors(t *testing.T) {
+	var p, err = NewFpeProcessor(Config{Key: commonKey128, Field: RoutingNumberField})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	var errorTests = []struct {
+		rtn string
+		err error
+	}{
+		{"", ErrInvalidRoutingNumber},
+		{"02100002", ErrInvalidRoutingNumber},
+		{"0210000210", ErrInvalidRoutingNumber},
+		{"021/000/021", ErrInvalidRoutingNumber},
+		{"500000005", ErrInvalidRoutingNumber},
+		{"021000022", ErrInvalidCheckDigit},
+	}
+	for _, test := range errorTests {
+		if _, errEnc := p.Encrypt(test.rtn); errEnc != test.err {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), test.rtn, errEnc, test.err)
+		}
+	}
+}
+
+func TestAccountNumberEncryptDecrypt(t *testing.T) {
+	for _, a := range configAlgorithms {
+		var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: AccountNumberField})
+		if err != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+
+		for _, account := range []string{"0001", "000123456789", "12345678901234567", "0012-3456-78"} {
+			var enc, errEnc = p.Encrypt(account)
+			if errEnc != nil {
+				t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, account, errEnc)
+				continue
+			}
+			if len(enc) != len(account) || strings.IndexByte(enc, '-') != strings.IndexByte(account, '-') {
+				t.Errorf("%s(%s): %s does not have the format of %s", t.Name(), a.alg, enc, account)
+			}
+
+			var dec, errDec = p.Decrypt(enc)
+			if errDec != nil {
+				t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, account, errDec)
+				continue
+			}
+			if strings.Compare(dec, account) != 0 {
+				t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, account)
+			}
+		}
+	}
+}
+
+func TestAccountNumberErrors(t *testing.T) {
+	var p, err = NewFpeProcessor(Config{Key: commonKey128, Field: AccountNumberField})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	for _, account := range []string{"", "123", "123456789012345678", "1234A"} {
+		if _, errEnc := p.Encrypt(account); errEnc != ErrInvalidAccountNumber {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), account, errEnc, ErrInvalidAccountNumber)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// A decimal amount is written with an optional sign or currency symbol, an integer part
+// that may be grouped by thousands separators, and an optional fractional part after the
+// decimal mark, e.g. "-1234.56", "$1,234,567.89" or "1.234.567,89 €".
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrInvalidAmount is returned when an amount has no digit or several decimal marks. When
+// the order of magnitude is kept, an integer part of several digits must not start with 0.
+// With MaxIntegerDigits, the integer part must not be empty, must have at most
+// MaxIntegerDigits digits, and must be written without leading zeros and grouped by
+// thousands with ThousandsSeparator, or not grouped if ThousandsSeparator is zero.
+var ErrInvalidAmount = errors.New("helper: invalid amount")
+
+// AmountOptions configures the processors of an AmountField.
+type AmountOptions struct {
+	// DecimalMark separates the integer and fractional parts, '.' by default.
+	DecimalMark rune
+	// MaxIntegerDigits is the maximum number of digits of the integer part. If zero, the
+	// number of digits of the integer part, hence the order of magnitude, is kept.
+	// Otherwise, the amount is enciphered as any amount with at most MaxIntegerDigits
+	// integer digits, and the integer part is written without leading zeros and grouped
+	// with ThousandsSeparator.
+	MaxIntegerDigits int
+	// ThousandsSeparator groups the integer part by thousands with MaxIntegerDigits, e.g.
+	// ',' for "1,234.56". If zero, the integer part is not grouped. Without
+	// MaxIntegerDigits, separators are left in place and ThousandsSeparator is not used.
+	ThousandsSeparator rune
+}
+
+// amountCodec enciphers the digits of amounts. The sign, the currency symbols, the
+// decimal mark and the number of fractional digits (the scale) are left unchanged.
+type amountCodec struct {
+	opts AmountOptions
+}
+
+func newAmountCodec(opts AmountOptions) (*amountCodec, error) {
+	if opts.DecimalMark == 0 {
+		opts.DecimalMark = '.'
+	}
+	if opts.DecimalMark >= '0' && opts.DecimalMark <= '9' {
+		return nil, fmt.Errorf("newAmountCodec: invalid decimal mark %q", opts.DecimalMark)
+	}
+	if opts.ThousandsSeparator >= '0' && opts.ThousandsSeparator <= '9' || opts.ThousandsSeparator == opts.DecimalMark {
+		return nil, fmt.Errorf("newAmountCodec: invalid thousands separator %q", opts.ThousandsSeparator)
+	}
+	if opts.MaxIntegerDigits < 0 {
+		return nil, fmt.Errorf("newAmountCodec: negative maximum number of integer digits %d", opts.MaxIntegerDigits)
+	}
+	return &amountCodec{opts}, nil
+}
+
+func (c *amountCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var runes = []rune(in)
+	var numeralString = make([]uint16, 0, len(runes))
+	var mark, intLen = -1, 0
+
+	// Create numeral string
+	for i, r := range runes {
+		switch {
+		case r >= '0' && r <= '9':
+			numeralString = append(numeralString, uint16(r-'0'))
+			if mark < 0 {
+				intLen++
+			}
+		case r == c.opts.DecimalMark:
+			if mark >= 0 {
+				return "", ErrInvalidAmount
+			}
+			mark = i
+		}
+	}
+	if len(numeralString) == 0 {
+		return "", ErrInvalidAmount
+	}
+
+	if c.opts.MaxIntegerDigits > 0 {
+		return c.cryptValue(newMode, runes, numeralString, mark, intLen)
+	}
+
+	// The first digit of an integer part of several digits is not 0, so that the order of
+	// magnitude of the amount is kept.
+	if intLen > 1 {
+		if numeralString[0] == 0 {
+			return "", ErrInvalidAmount
+		}
+		if len(numeralString) < minDecimalLen+1 {
+			return "", ErrDomainTooSmall
+		}
+	} else if len(numeralString) < minDecimalLen {
+		return "", ErrDomainTooSmall
+	}
+	var err = cryptDigits(newMode, runes, 0, len(numeralString), func(numeralString []uint16) bool {
+		return intLen < 2 || numeralString[0] != 0
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(runes), nil
+}
+
+// cryptValue enciphers the value of an amount with at most MaxIntegerDigits integer
+// digits, scaled to an integer, and formats the result back.
+func (c *amountCodec) cryptValue(newMode modeFunc, runes []rune, numeralString []uint16, mark, intLen int) (string, error) {
+	if intLen == 0 {
+		return "", ErrInvalidAmount
+	}
+
+	// The integer part is made of digits and thousands separators
+	var start, end = -1, mark
+	for i, r := range runes {
+		if r >= '0' && r <= '9' {
+			if start < 0 {
+				start = i
+			}
+			if mark < 0 {
+				end = i + 1
+			}
+		}
+	}
+	// The integer part must be formatted as formatInteger writes it, so that the amount is
+	// deciphered as it is written
+	if string(runes[start:end]) != string(c.formatInteger(numeralStringToDecimal(numeralString[:intLen]))) {
+		return "", ErrInvalidAmount
+	}
+
+	var value = numeralStringToInt(numeralString, decimalRadix)
+	var scale = len(numeralString) - intLen
+	var n = new(big.Int).Exp(big.NewInt(decimalRadix), big.NewInt(int64(c.opts.MaxIntegerDigits+scale)), nil)
+	if value.Cmp(n) >= 0 {
+		return "", ErrInvalidAmount
+	}
+	var y, err = cryptInt(newMode, n, value)
+	if err != nil {
+		return "", err
+	}
+
+	var digits = intToNumeralString(y, decimalRadix, c.opts.MaxIntegerDigits+scale)
+	var out = make([]rune, 0, len(runes)+c.opts.MaxIntegerDigits)
+	out = append(out, runes[:start]...)
+	out = append(out, c.formatInteger(numeralStringToDecimal(digits[:c.opts.MaxIntegerDigits]))...)
+
+	var numStrIdx = c.opts.MaxIntegerDigits
+	// Copy enciphered fractional digits
+	for _, r := range runes[end:] {
+		if r >= '0' && r <= '9' {
+			r = rune('0' + digits[numStrIdx])
+			numStrIdx++
+		}
+		out = append(out, r)
+	}
+
+	return string(out), nil
+}
+
+// formatInteger writes the decimal digits of an integer part without leading zeros,
+// grouped by thousands with the thousands separator.
+func (c *amountCodec) formatInteger(digits string) []rune {
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		digits = "0"
+	}
+	var out = make([]rune, 0, 2*len(digits))
+	for i, r := range digits {
+		if c.opts.ThousandsSeparator != 0 && i > 0 && (len(digits)-i)%3 == 0 {
+			out = append(out, c.opts.ThousandsSeparator)
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// numeralStringToDecimal writes a decimal numeral string with the digits 0-9.
+func numeralStringToDecimal(numeralString []uint16) string {
+	var digits = make([]byte, len(numeralString))
+	for i, num := range numeralString {
+		digits[i] = byte('0' + num)
+	}
+	return string(digits)
+}
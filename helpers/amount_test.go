@@ -0,0 +1,205 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// amountStructure returns the amount with its digits replaced by 'd', except the first
+// one of an integer part of several digits, replaced by 'D' when it is not 0.
+func amountStructure(amount string, mark rune) string {
+	var runes = []rune(amount)
+	var first, intLen = -1, 0
+	for i, r := range runes {
+		if r == mark {
+			break
+		}
+		if r >= '0' && r <= '9' {
+			if first < 0 {
+				first = i
+			}
+			intLen++
+		}
+	}
+	for i, r := range runes {
+		if r >= '0' && r <= '9' {
+			if i == first && intLen > 1 && r != '0' {
+				runes[i] = 'D'
+			} else {
+				runes[i] = 'd'
+			}
+		}
+	}
+	return string(runes)
+}
+
+func TestAmountKeepMagnitude(t *testing.T) {
+	var amountTests = []struct {
+		mark    rune
+		amounts []string
+	}{
+		{0, []string{"-1234.56", "+0.99", "$1,234,567.89", "(12.00)", "1234", "0.05", "100"}},
+		{',', []string{"1.234.567,89 €", "-12,5", "CHF 1'000,00", "0,001"}},
+	}
+
+	for _, a := range configAlgorithms {
+		for _, test := range amountTests {
+			var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: AmountField,
+				Amount: AmountOptions{DecimalMark: test.mark}})
+			if err != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+			}
+
+			for _, amount := range test.amounts {
+				var enc, errEnc = p.Encrypt(amount)
+				if errEnc != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, amount, errEnc)
+					continue
+				}
+				var mark = test.mark
+				if mark == 0 {
+					mark = '.'
+				}
+				if strings.Compare(amountStructure(enc, mark), amountStructure(amount, mark)) != 0 {
+					t.Errorf("%s(%s): %s does not have the structure of %s", t.Name(), a.alg, enc, amount)
+				}
+
+				var dec, errDec = p.Decrypt(enc)
+				if errDec != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, amount, errDec)
+					continue
+				}
+				if strings.Compare(dec, amount) != 0 {
+					t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, amount)
+				}
+			}
+		}
+	}
+}
+
+func TestAmountMaxIntegerDigits(t *testing.T) {
+	var amountTests = []struct {
+		mark      rune
+		separator rune
+		amounts   []string
+	}{
+		{0, ',', []string{"-1,234.56", "$0.99", "7", "1,234,567", "USD 999,999.00"}},
+		{0, 0, []string{"1234567", "-0.5"}},
+		{',', '.', []string{"1.234.567,89 €", "-0,50"}},
+	}
+
+	for _, a := range configAlgorithms {
+		for _, test := range amountTests {
+			var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: AmountField,
+				Amount: AmountOptions{DecimalMark: test.mark, MaxIntegerDigits: 7, ThousandsSeparator: test.separator}})
+			if err != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+			}
+
+			for _, amount := range test.amounts {
+				var enc, errEnc = p.Encrypt(amount)
+				if errEnc != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, amount, errEnc)
+					continue
+				}
+
+				// Sign, currency and scale are kept
+				var prefix = amount[:strings.IndexAny(amount, "0123456789")]
+				var mark = strings.LastIndex(amount, string(test.mark))
+				var encMark = strings.LastIndex(enc, string(test.mark))
+				if !strings.HasPrefix(enc, prefix) || (mark >= 0) != (encMark >= 0) ||
+					(mark >= 0 && len(enc)-encMark != len(amount)-mark) {
+					t.Errorf("%s(%s): %s does not have the sign and scale of %s", t.Name(), a.alg, enc, amount)
+				}
+
+				var dec, errDec = p.Decrypt(enc)
+				if errDec != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, amount, errDec)
+					continue
+				}
+				if strings.Compare(dec, amount) != 0 {
+					t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, amount)
+				}
+			}
+		}
+	}
+}
+
+func TestAmountThousandsSeparator(t *testing.T) {
+	for _, a := range configAlgorithms {
+		var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: AmountField,
+			Amount: AmountOptions{MaxIntegerDigits: 4, ThousandsSeparator: ','}})
+		if err != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+
+		// Grouped amounts enciphered into amounts of 3 integer digits or less, which have no
+		// separator, and back
+		var short = 0
+		for i := 0; i < 300; i++ {
+			var amount = fmt.Sprintf("1,%03d.%02d", i, i%100)
+			var enc, errEnc = p.Encrypt(amount)
+			if errEnc != nil {
+				t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, amount, errEnc)
+				continue
+			}
+			if !strings.Contains(enc, ",") {
+				short++
+			}
+			var dec, errDec = p.Decrypt(enc)
+			if errDec != nil {
+				t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, amount, errDec)
+				continue
+			}
+			if strings.Compare(dec, amount) != 0 {
+				t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, amount)
+			}
+		}
+		if short == 0 {
+			t.Errorf("%s(%s): no amount enciphered into an amount without separator", t.Name(), a.alg)
+		}
+	}
+}
+
+func TestAmountErrors(t *testing.T) {
+	var amountTests = []struct {
+		opts   AmountOptions
+		amount string
+		err    error
+	}{
+		{AmountOptions{}, "", ErrInvalidAmount},
+		{AmountOptions{}, "USD", ErrInvalidAmount},
+		{AmountOptions{}, "1.234.56", ErrInvalidAmount},
+		{AmountOptions{}, "0123.45", ErrInvalidAmount},
+		{AmountOptions{}, "5", ErrDomainTooSmall},
+		{AmountOptions{}, "12", ErrDomainTooSmall},
+		{AmountOptions{}, "1.5", nil},
+		{AmountOptions{MaxIntegerDigits: 3}, "1,000", ErrInvalidAmount},
+		{AmountOptions{MaxIntegerDigits: 3}, ".50", ErrInvalidAmount},
+		{AmountOptions{MaxIntegerDigits: 7}, "1,000 000", ErrInvalidAmount},
+		{AmountOptions{MaxIntegerDigits: 1}, "5", ErrDomainTooSmall},
+		{AmountOptions{MaxIntegerDigits: 7, ThousandsSeparator: ','}, "1000", ErrInvalidAmount},
+		{AmountOptions{MaxIntegerDigits: 7, ThousandsSeparator: ','}, "01,000", ErrInvalidAmount},
+		{AmountOptions{MaxIntegerDigits: 7, ThousandsSeparator: ','}, "10,00", ErrInvalidAmount},
+		{AmountOptions{MaxIntegerDigits: 7}, "007.50", ErrInvalidAmount},
+		{AmountOptions{MaxIntegerDigits: 7, ThousandsSeparator: ','}, "1,000", nil},
+	}
+
+	for _, test := range amountTests {
+		var p, err = NewFpeProcessor(Config{Key: commonKey128, Field: AmountField, Amount: test.opts})
+		if err != nil {
+			t.Fatalf("%s: %s", t.Name(), err)
+		}
+		if _, errEnc := p.Encrypt(test.amount); errEnc != test.err {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), test.amount, errEnc, test.err)
+		}
+	}
+
+	if _, err := NewFpeProcessor(Config{Key: commonKey128, Field: AmountField, Amount: AmountOptions{DecimalMark: '5'}}); err == nil {
+		t.Errorf("%s: digit decimal mark should be rejected", t.Name())
+	}
+	if _, err := NewFpeProcessor(Config{Key: commonKey128, Field: AmountField, Amount: AmountOptions{ThousandsSeparator: '.'}}); err == nil {
+		t.Errorf("%s: thousands separator equal to the decimal mark should be rejected", t.Name())
+	}
+}
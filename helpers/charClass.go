@@ -0,0 +1,53 @@
+package helper
+
+const (
+	upperAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	lowerAlphabet = "abcdefghijklmnopqrstuvwxyz"
+	digitAlphabet = "0123456789"
+)
+
+var (
+	upperCodec, _ = newStringCodec(upperAlphabet)
+	lowerCodec, _ = newStringCodec(lowerAlphabet)
+	digitCodec, _ = newStringCodec(digitAlphabet)
+)
+
+// charClassCodec enciphers free-form identifiers while keeping the class of every
+// character: ASCII uppercase letters stay uppercase letters, lowercase letters stay
+// lowercase letters and digits stay digits. All other characters are left in place.
+// The identifier is enciphered as a whole, so that it must have at least 100 possible
+// values, otherwise ErrDomainTooSmall is returned.
+type charClassCodec struct{}
+
+func (c charClassCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var runes = []rune(in)
+	var positions = make([]int, 0, len(runes))
+	var alphabets = make([]*stringCodec, 0, len(runes))
+
+	for i, r := range runes {
+		var codec = charClassOf(r)
+		if codec != nil {
+			positions = append(positions, i)
+			alphabets = append(alphabets, codec)
+		}
+	}
+
+	if err := cryptMixedRadix(newMode, runes, positions, alphabets); err != nil {
+		return "", err
+	}
+	return string(runes), nil
+}
+
+// charClassOf returns the alphabet of the class of r, or nil if r is left in place.
+func charClassOf(r rune) *stringCodec {
+	switch {
+	case r >= 'A' && r <= 'Z':
+		return upperCodec
+	case r >= 'a' && r <= 'z':
+		return lowerCodec
+	case r >= '0' && r <= '9':
+		return digitCodec
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,85 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCharClassEncryptDecrypt(t *testing.T) {
+	for _, a := range configAlgorithms {
+		var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: CharClassField})
+		if err != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+
+		for _, in := range []string{"Ab12-cd", "X7", "ORDER-2024-000123", "user.name+tag", "aZ9 éß_0", "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"} {
+			var enc, errEnc = p.Encrypt(in)
+			if errEnc != nil {
+				t.Errorf("%s(%s): %s", t.Name(), a.alg, errEnc)
+				continue
+			}
+
+			// Every position keeps its class
+			var inRunes, encRunes = []rune(in), []rune(enc)
+			if len(inRunes) != len(encRunes) {
+				t.Errorf("%s(%s): %q and %q have different lengths", t.Name(), a.alg, enc, in)
+				continue
+			}
+			for i := range inRunes {
+				var inClass, encClass = charClassOf(inRunes[i]), charClassOf(encRunes[i])
+				if inClass != encClass || (inClass == nil && inRunes[i] != encRunes[i]) {
+					t.Errorf("%s(%s): %q does not have the shape of %q", t.Name(), a.alg, enc, in)
+					break
+				}
+			}
+
+			var dec, errDec = p.Decrypt(enc)
+			if errDec != nil {
+				t.Errorf("%s(%s): %s", t.Name(), a.alg, errDec)
+				continue
+			}
+			if strings.Compare(dec, in) != 0 {
+				t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, in)
+			}
+		}
+	}
+}
+
+func TestCharClassDomainTooSmall(t *testing.T) {
+	var p, err = NewFpeProcessor(Config{Key: commonKey128, Field: CharClassField})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	for _, in := range []string{"", "--", "A", "7-", "é1"} {
+		if _, errEnc := p.Encrypt(in); errEnc != ErrDomainTooSmall {
+			t.Errorf("%s(%q):\nhave %v\nwant %v", t.Name(), in, errEnc, ErrDomainTooSmall)
+		}
+	}
+}
+
+func TestCharClassMaxLength(t *testing.T) {
+	// 52 letters are enciphered as a 74-digit number, above the 56 digits of FF3 and FF3-1
+	var in = strings.Repeat("abcdefghijklmnopqrstuvwxyz", 2)
+	for _, a := range configAlgorithms {
+		var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: CharClassField})
+		if err != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+
+		var enc, errEnc = p.Encrypt(in)
+		if a.alg != FF1 {
+			if _, isLengthError := errEnc.(*NumeralStringLengthError); !isLengthError {
+				t.Errorf("%s(%s): Encrypt(%s) = %v", t.Name(), a.alg, in, errEnc)
+			}
+			continue
+		}
+		if errEnc != nil {
+			t.Errorf("%s(%s): %s", t.Name(), a.alg, errEnc)
+			continue
+		}
+		if dec, errDec := p.Decrypt(enc); errDec != nil || dec != in {
+			t.Errorf("%s(%s):\nhave %s %v\nwant %s", t.Name(), a.alg, dec, errDec, in)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidCheckDigit is returned when the check characters of a value are invalid.
+var ErrInvalidCheckDigit = errors.New("helper: invalid check digit")
+
+// ErrCheckDigitPayload is returned when a value has characters that its check character
+// system does not accept, e.g. letters with Luhn.
+var ErrCheckDigitPayload = errors.New("helper: invalid characters for the check digit scheme")
+
+// CheckDigit is a check character system. Strings are made of the significant characters
+// of a value, without separators.
+type CheckDigit interface {
+	// Compute returns the check characters of a payload, the string without its check
+	// characters. It returns an error if the payload has invalid characters.
+	Compute(payload string) (string, error)
+	// Validate tells if a string has valid check characters.
+	Validate(s string) bool
+	// Position returns the indexes [start, end) of the check characters in a string of
+	// n characters.
+	Position(n int) (start, end int)
+}
+
+var (
+	// Luhn is the Luhn (mod 10) check digit of credit card numbers and IMEIs.
+	Luhn CheckDigit = trailingCheck{1, luhnCheck}
+	// Verhoeff is the Verhoeff check digit, which detects all single-digit errors and
+	// adjacent transpositions.
+	Verhoeff CheckDigit = trailingCheck{1, verhoeffCheck}
+	// Damm is the Damm check digit, which detects all single-digit errors and adjacent
+	// transpositions.
+	Damm CheckDigit = trailingCheck{1, dammCheck}
+	// Mod11Radix2 is the ISO 7064 MOD 11-2 check character of digit strings, 0-9 or X,
+	// as in ORCID and ISNI identifiers.
+	Mod11Radix2 CheckDigit = trailingCheck{1, mod11Radix2Check}
+	// Mod97Radix10 is the ISO 7064 MOD 97-10 pair of check digits. Letters of the payload
+	// are replaced by two digits (A = 10, ..., Z = 35), as in IBANs.
+	Mod97Radix10 CheckDigit = trailingCheck{2, mod97Radix10Check}
+	// Mod37Radix2 is the ISO 7064 MOD 37-2 check character of alphanumeric strings, 0-9,
+	// A-Z or *.
+	Mod37Radix2 CheckDigit = trailingCheck{1, mod37Radix2Check}
+)
+
+// trailingCheck is a check character system with k check characters at the end of the
+// string.
+type trailingCheck struct {
+	k       int
+	compute func(payload string) (string, error)
+}
+
+func (c trailingCheck) Compute(payload string) (string, error) {
+	return c.compute(payload)
+}
+
+func (c trailingCheck) Validate(s string) bool {
+	return validateCheckDigit(c, s)
+}
+
+func (c trailingCheck) Position(n int) (int, int) {
+	return n - c.k, n
+}
+
+// validateCheckDigit tells if s has valid check characters, by computing them from the
+// other characters.
+func validateCheckDigit(c CheckDigit, s string) bool {
+	var start, end = c.Position(len(s))
+	if start < 0 || start > end || end > len(s) {
+		return false
+	}
+	var check, err = c.Compute(s[:start] + s[end:])
+	return err == nil && check == s[start:end]
+}
+
+// decimalNumeralString returns the numeral string of a string of decimal digits.
+func decimalNumeralString(s string) ([]uint16, error) {
+	var numeralString = make([]uint16, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return nil, fmt.Errorf("decimalNumeralString: Character %q at index %d is not a digit", s[i], i)
+		}
+		numeralString[i] = uint16(s[i] - '0')
+	}
+	return numeralString, nil
+}
+
+func luhnCheck(payload string) (string, error) {
+	var numeralString, err = decimalNumeralString(payload)
+	if err != nil {
+		return "", err
+	}
+	return string(rune('0' + luhnChecksum(numeralString))), nil
+}
+
+var (
+	verhoeffMultiplication = [10][10]uint16{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 2, 3, 4, 0, 6, 7, 8, 9, 5},
+		{2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+		{3, 4, 0, 1, 2, 8, 9, 5, 6, 7},
+		{4, 0, 1, 2, 3, 9, 5, 6, 7, 8},
+		{5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+		{6, 5, 9, 8, 7, 1, 0, 4, 3, 2},
+		{7, 6, 5, 9, 8, 2, 1, 0, 4, 3},
+		{8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+	}
+	verhoeffPermutation = [8][10]uint16{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 5, 7, 6, 2, 8, 3, 0, 9, 4},
+		{5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+		{8, 9, 1, 6, 0, 4, 3, 5, 2, 7},
+		{9, 4, 5, 3, 1, 2, 6, 8, 7, 0},
+		{4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+		{2, 7, 9, 3, 8, 0, 6, 4, 1, 5},
+		{7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+	}
+	verhoeffInverse = [10]uint16{0, 4, 3, 2, 1, 5, 6, 7, 8, 9}
+)
+
+func verhoeffCheck(payload string) (string, error) {
+	var numeralString, err = decimalNumeralString(payload)
+	if err != nil {
+		return "", err
+	}
+
+	// The check digit takes position 0, so payload digits start at position 1 from the right
+	var c = uint16(0)
+	for i := 0; i < len(numeralString); i++ {
+		c = verhoeffMultiplication[c][verhoeffPermutation[(i+1)%8][numeralString[len(numeralString)-i-1]]]
+	}
+	return string(rune('0' + verhoeffInverse[c])), nil
+}
+
+// dammQuasigroup is the totally anti-symmetric quasigroup of order 10 of Damm's thesis.
+var dammQuasigroup = [10][10]uint16{
+	{0, 3, 1, 7, 5, 9, 8, 6, 4, 2},
+	{7, 0, 9, 2, 1, 5, 4, 8, 6, 3},
+	{4, 2, 0, 6, 8, 7, 1, 3, 5, 9},
+	{1, 7, 5, 0, 9, 8, 3, 4, 2, 6},
+	{6, 1, 2, 3, 0, 4, 5, 9, 7, 8},
+	{3, 6, 7, 4, 2, 0, 9, 5, 8, 1},
+	{5, 8, 6, 9, 7, 2, 0, 1, 3, 4},
+	{8, 9, 4, 5, 3, 6, 2, 0, 1, 7},
+	{9, 4, 3, 8, 6, 1, 7, 2, 0, 5},
+	{2, 5, 8, 1, 4, 3, 6, 7, 9, 0},
+}
+
+func dammCheck(payload string) (string, error) {
+	var numeralString, err = decimalNumeralString(payload)
+	if err != nil {
+		return "", err
+	}
+
+	var interim = uint16(0)
+	for _, num := range numeralString {
+		interim = dammQuasigroup[interim][num]
+	}
+	return string(rune('0' + interim)), nil
+}
+
+// iso7064PureCheck computes the check character of an ISO 7064 pure system with modulus
+// m and radix r. The characters of the payload are valued by their index in alphabet,
+// which also gives the check characters.
+func iso7064PureCheck(payload string, m, r int, alphabet string) (string, error) {
+	var p = 0
+	for i, c := range payload {
+		var v = strings.IndexRune(alphabet[:m-1], c)
+		if v < 0 {
+			return "", fmt.Errorf("iso7064PureCheck: Character %q at index %d not in alphabet", c, i)
+		}
+		p = (p + v) * r % m
+	}
+	return string(alphabet[(m+1-p)%m]), nil
+}
+
+func mod11Radix2Check(payload string) (string, error) {
+	return iso7064PureCheck(payload, 11, 2, digitAlphabet+"X")
+}
+
+func mod37Radix2Check(payload string) (string, error) {
+	return iso7064PureCheck(payload, 37, 2, digitAlphabet+upperAlphabet+"*")
+}
+
+func mod97Radix10Check(payload string) (string, error) {
+	var remainder = 0
+	for i, c := range payload {
+		var v = strings.IndexRune(alnumAlphabet, c)
+		switch {
+		case v < 0:
+			return "", fmt.Errorf("mod97Radix10Check: Character %q at index %d not in alphabet", c, i)
+		case v < 10:
+			remainder = (remainder*10 + v) % 97
+		default:
+			remainder = (remainder*100 + v) % 97
+		}
+	}
+	return fmt.Sprintf("%02d", 98-remainder*100%97), nil
+}
+
+// CheckDigitOptions configures the processors of a CheckDigitField.
+type CheckDigitOptions struct {
+	// Scheme is the check character system of the values.
+	Scheme CheckDigit
+	// KeepLeading is the number of leading characters of the payload left in clear, e.g.
+	// an issuer prefix.
+	KeepLeading int
+}
+
+// checkDigitCodec enciphers the payload of values protected by check characters, and
+// recomputes the check characters. Letters and digits of the payload stay in their class,
+// as with a CharClassField. Characters other than ASCII letters, digits and '*' are
+// separators, left in place. The check characters must be valid, otherwise
+// ErrInvalidCheckDigit is returned.
+type checkDigitCodec struct {
+	opts CheckDigitOptions
+}
+
+func newCheckDigitCodec(opts CheckDigitOptions) (*checkDigitCodec, error) {
+	if opts.Scheme == nil {
+		return nil, fmt.Errorf("newCheckDigitCodec: no check digit scheme")
+	}
+	if opts.KeepLeading < 0 {
+		return nil, fmt.Errorf("newCheckDigitCodec: negative number of leading digits %d", opts.KeepLeading)
+	}
+	return &checkDigitCodec{opts}, nil
+}
+
+func (c *checkDigitCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var runes = []rune(in)
+	if err := cryptCheckDigit(newMode, c.opts.Scheme, runes, c.opts.KeepLeading, nil); err != nil {
+		return "", err
+	}
+	return string(runes), nil
+}
+
+// isCheckDigitSeparator tells if r is a separator of a value protected by check characters.
+func isCheckDigitSeparator(r rune) bool {
+	return !(r >= '0' && r <= '9') && !(r >= 'A' && r <= 'Z') && !(r >= 'a' && r <= 'z') && r != '*'
+}
+
+// cryptCheckDigit enciphers in place the payload of runes, except its first keep
+// characters, and recomputes the check characters of scheme. Separators are left in
+// place. A payload of digits is enciphered as a decimal numeral string, cycle-walked until
+// valid accepts it if valid is not nil. A payload with letters is enciphered keeping
+// letters and digits in their class, and valid is not used.
+func cryptCheckDigit(newMode modeFunc, scheme CheckDigit, runes []rune, keep int, valid func([]uint16) bool) error {
+	var positions = make([]int, 0, len(runes))
+	var significant = make([]rune, 0, len(runes))
+	for i, r := range runes {
+		if !isCheckDigitSeparator(r) {
+			positions = append(positions, i)
+			significant = append(significant, r)
+		}
+	}
+	var start, end = scheme.Position(len(significant))
+	if start < 0 || start > end || end > len(significant) {
+		return ErrInvalidCheckDigit
+	}
+
+	// Extract the payload, the significant characters except the check characters
+	var payload = make([]int, 0, len(positions))
+	var alphabets = make([]*stringCodec, 0, len(positions))
+	var digitsOnly = true
+	for k, i := range positions {
+		if k >= start && k < end {
+			continue
+		}
+		var codec = charClassOf(runes[i])
+		if codec == nil {
+			return ErrCheckDigitPayload
+		}
+		digitsOnly = digitsOnly && codec == digitCodec
+		payload = append(payload, i)
+		alphabets = append(alphabets, codec)
+	}
+	var check, err = scheme.Compute(payloadString(runes, payload))
+	if err != nil {
+		return ErrCheckDigitPayload
+	}
+	if check != string(significant[start:end]) {
+		return ErrInvalidCheckDigit
+	}
+	if keep > len(payload) {
+		return ErrDomainTooSmall
+	}
+
+	if digitsOnly {
+		err = cryptDecimalPositions(newMode, runes, payload[keep:], valid)
+	} else {
+		err = cryptMixedRadix(newMode, runes, payload[keep:], alphabets[keep:])
+	}
+	if err != nil {
+		return err
+	}
+
+	// Compute ciphertext check characters
+	if check, err = scheme.Compute(payloadString(runes, payload)); err != nil {
+		return err
+	}
+	if len(check) != end-start {
+		return fmt.Errorf("cryptCheckDigit: %d check characters instead of %d", len(check), end-start)
+	}
+	for k, r := range check {
+		runes[positions[start+k]] = r
+	}
+	return nil
+}
+
+// payloadString returns the characters of runes at the given positions.
+func payloadString(runes []rune, positions []int) string {
+	var payload = make([]rune, len(positions))
+	for k, i := range positions {
+		payload[k] = runes[i]
+	}
+	return string(payload)
+}
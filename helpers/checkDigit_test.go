@@ -0,0 +1,180 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+)
+
+var checkDigitTests = []struct {
+	name    string
+	scheme  CheckDigit
+	payload string
+	check   string
+}{
+	{"Luhn", Luhn, "7992739871", "3"},
+	{"Luhn", Luhn, "453201511283036", "6"},
+	{"Verhoeff", Verhoeff, "236", "3"},
+	{"Verhoeff", Verhoeff, "12345", "1"},
+	{"Damm", Damm, "572", "4"},
+	{"Mod11Radix2", Mod11Radix2, "000000021825009", "7"},
+	{"Mod11Radix2", Mod11Radix2, "000000021694233", "X"},
+	{"Mod97Radix10", Mod97Radix10, "794", "44"},
+	{"Mod97Radix10", Mod97Radix10, "WEST12345698765432GB", "82"},
+	{"Mod37Radix2", Mod37Radix2, "G123498654321", "H"},
+}
+
+func TestCheckDigitCompute(t *testing.T) {
+	for _, test := range checkDigitTests {
+		var check, err = test.scheme.Compute(test.payload)
+		if err != nil {
+			t.Errorf("%s(%s): %s", t.Name(), test.name, err)
+			continue
+		}
+		if strings.Compare(check, test.check) != 0 {
+			t.Errorf("%s(%s, %s):\nhave %s\nwant %s", t.Name(), test.name, test.payload, check, test.check)
+		}
+		if !test.scheme.Validate(test.payload + test.check) {
+			t.Errorf("%s(%s): %s should be valid", t.Name(), test.name, test.payload+test.check)
+		}
+	}
+
+	for _, scheme := range []CheckDigit{Luhn, Verhoeff, Damm, Mod11Radix2, Mod97Radix10, Mod37Radix2} {
+		if _, err := scheme.Compute("12-4"); err == nil {
+			t.Errorf("%s: invalid payload should be rejected", t.Name())
+		}
+	}
+	for _, scheme := range []CheckDigit{Luhn, Verhoeff, Damm, Mod11Radix2} {
+		if _, err := scheme.Compute("12A4"); err == nil {
+			t.Errorf("%s: letters should be rejected", t.Name())
+		}
+	}
+}
+
+func TestCheckDigitDetectsErrors(t *testing.T) {
+	var schemes = []struct {
+		name          string
+		scheme        CheckDigit
+		alphabet      string
+		payload       string
+		transposition bool
+	}{
+		{"Luhn", Luhn, digitAlphabet, "4532015112830", false},
+		{"Verhoeff", Verhoeff, digitAlphabet, "4532015112830", true},
+		{"Damm", Damm, digitAlphabet, "4532015112830", true},
+		{"Mod11Radix2", Mod11Radix2, digitAlphabet, "4532015112830", true},
+		{"Mod97Radix10", Mod97Radix10, digitAlphabet, "4532015112830", true},
+		{"Mod37Radix2", Mod37Radix2, digitAlphabet + upperAlphabet, "G123498654321", true},
+	}
+
+	for _, test := range schemes {
+		var check, err = test.scheme.Compute(test.payload)
+		if err != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), test.name, err)
+		}
+		var value = []byte(test.payload + check)
+
+		// Single substitutions
+		for i := range test.payload {
+			var original = value[i]
+			for j := 0; j < len(test.alphabet); j++ {
+				value[i] = test.alphabet[j]
+				if value[i] != original && test.scheme.Validate(string(value)) {
+					t.Errorf("%s(%s): substitution %s not detected", t.Name(), test.name, value)
+				}
+			}
+			value[i] = original
+		}
+
+		// Adjacent transpositions
+		for i := 0; test.transposition && i+1 < len(test.payload); i++ {
+			if value[i] == value[i+1] {
+				continue
+			}
+			value[i], value[i+1] = value[i+1], value[i]
+			if test.scheme.Validate(string(value)) {
+				t.Errorf("%s(%s): transposition %s not detected", t.Name(), test.name, value)
+			}
+			value[i], value[i+1] = value[i+1], value[i]
+		}
+	}
+}
+
+func TestCheckDigitEncryptDecrypt(t *testing.T) {
+	var values = []struct {
+		name   string
+		opts   CheckDigitOptions
+		values []string
+	}{
+		{"Luhn", CheckDigitOptions{Scheme: Luhn}, []string{"79927398713", "4532-0151-1283-0366"}},
+		{"Verhoeff", CheckDigitOptions{Scheme: Verhoeff}, []string{"2363", "1234 5678 9012 0"}},
+		{"Damm", CheckDigitOptions{Scheme: Damm}, []string{"5724", "98765432103"}},
+		{"Damm", CheckDigitOptions{Scheme: Damm, KeepLeading: 4}, []string{"9876-5432-103"}},
+		{"Mod11Radix2", CheckDigitOptions{Scheme: Mod11Radix2}, []string{"0000-0002-1694-233X", "0000-0002-1825-0097"}},
+		{"Mod97Radix10", CheckDigitOptions{Scheme: Mod97Radix10}, []string{"79444", "12345678901234567888", "AB12CD3466"}},
+		{"Mod37Radix2", CheckDigitOptions{Scheme: Mod37Radix2}, []string{"ABC123G", "G123498654321H"}},
+		{"Mod37Radix2", CheckDigitOptions{Scheme: Mod37Radix2, KeepLeading: 2}, []string{"AB-12CD34-T"}},
+	}
+
+	for _, a := range configAlgorithms {
+		for _, test := range values {
+			var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: CheckDigitField, CheckDigit: test.opts})
+			if err != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+			}
+
+			for _, value := range test.values {
+				var enc, errEnc = p.Encrypt(value)
+				if errEnc != nil {
+					t.Errorf("%s(%s, %s, %s): %s", t.Name(), a.alg, test.name, value, errEnc)
+					continue
+				}
+				var compact = strings.Replace(strings.Replace(enc, "-", "", -1), " ", "", -1)
+				if len(enc) != len(value) || !test.opts.Scheme.Validate(compact) {
+					t.Errorf("%s(%s, %s): %s is not a valid value with the format of %s", t.Name(), a.alg, test.name, enc, value)
+				}
+				if enc[:test.opts.KeepLeading] != value[:test.opts.KeepLeading] {
+					t.Errorf("%s(%s, %s): %s does not keep the leading digits of %s", t.Name(), a.alg, test.name, enc, value)
+				}
+
+				var dec, errDec = p.Decrypt(enc)
+				if errDec != nil {
+					t.Errorf("%s(%s, %s, %s): %s", t.Name(), a.alg, test.name, value, errDec)
+					continue
+				}
+				if strings.Compare(dec, value) != 0 {
+					t.Errorf("%s(%s, %s):\nhave %s\nwant %s", t.Name(), a.alg, test.name, dec, value)
+				}
+			}
+		}
+	}
+}
+
+func TestCheckDigitErrors(t *testing.T) {
+	var errorTests = []struct {
+		opts  CheckDigitOptions
+		value string
+		err   error
+	}{
+		{CheckDigitOptions{Scheme: Luhn}, "79927398710", ErrInvalidCheckDigit},
+		{CheckDigitOptions{Scheme: Luhn}, "", ErrInvalidCheckDigit},
+		{CheckDigitOptions{Scheme: Mod37Radix2}, "G123498654321A", ErrInvalidCheckDigit},
+		{CheckDigitOptions{Scheme: Luhn}, "7992A398713", ErrCheckDigitPayload},
+		{CheckDigitOptions{Scheme: Luhn}, "18", ErrDomainTooSmall},
+		{CheckDigitOptions{Scheme: Luhn, KeepLeading: 9}, "79927398713", ErrDomainTooSmall},
+		{CheckDigitOptions{Scheme: Luhn, KeepLeading: 8}, "79927398713", nil},
+	}
+
+	for _, test := range errorTests {
+		var p, err = NewFpeProcessor(Config{Key: commonKey128, Field: CheckDigitField, CheckDigit: test.opts})
+		if err != nil {
+			t.Fatalf("%s: %s", t.Name(), err)
+		}
+		if _, errEnc := p.Encrypt(test.value); errEnc != test.err {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), test.value, errEnc, test.err)
+		}
+	}
+
+	if _, err := NewFpeProcessor(Config{Key: commonKey128, Field: CheckDigitField}); err == nil {
+		t.Errorf("%s: missing scheme should be rejected", t.Name())
+	}
+}
@@ -0,0 +1,320 @@
+package helper
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto-fpe/fpe"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Algorithm selects the format-preserving encryption mode used by a processor.
+type Algorithm int
+
+const (
+	// FF1 as specified in NIST SP 800-38G.
+	FF1 Algorithm = iota
+	// FF3 as specified in NIST SP 800-38G, with a 64-bit tweak.
+	FF3
+	// FF31 is FF3-1 as specified in NIST SP 800-38G Rev. 1, with a 56-bit tweak.
+	FF31
+)
+
+// unknownAlgorithm is the algorithm of the modes given to the legacy constructors, which
+// cannot be told apart. Their tweak lengths are not checked.
+const unknownAlgorithm Algorithm = -1
+
+// validAlgorithm reports whether alg is FF1, FF3 or FF31.
+func validAlgorithm(alg Algorithm) bool {
+	return alg == FF1 || alg == FF3 || alg == FF31
+}
+
+func (a Algorithm) String() string {
+	switch a {
+	case FF1:
+		return "FF1"
+	case FF3:
+		return "FF3"
+	case FF31:
+		return "FF3-1"
+	default:
+		return fmt.Sprintf("Algorithm(%d)", int(a))
+	}
+}
+
+// FieldType selects the kind of data a processor built from a Config handles.
+type FieldType int
+
+const (
+	// CreditCardField enciphers credit card numbers, see FpeCreditCard.
+	CreditCardField FieldType = iota
+	// StringField enciphers strings over Config.Alphabet, see FpeString.
+	StringField
+	// CharClassField enciphers free-form identifiers while keeping uppercase letters,
+	// lowercase letters and digits in their class, and other characters in place.
+	CharClassField
+	// IBANField enciphers IBANs, see FpeIBAN.
+	IBANField
+	// SSNField enciphers US Social Security Numbers into valid Social Security Numbers,
+	// keeping dash and space separators.
+	SSNField
+	// PhoneField enciphers the subscriber digits of phone numbers, keeping the country
+	// calling code and the formatting characters.
+	PhoneField
+	// EmailField enciphers email addresses, see FpeEmail.
+	EmailField
+	// DateField enciphers dates into dates of the range set in Config.Date.
+	DateField
+	// AmountField enciphers decimal amounts, keeping their sign and scale.
+	AmountField
+	// IPField enciphers the host bits of IPv4 and IPv6 addresses, keeping a network
+	// prefix set in Config.IP.
+	IPField
+	// MACField enciphers MAC addresses (EUI-48) and EUI-64, keeping the part set in
+	// Config.MAC and the separators.
+	MACField
+	// CheckDigitField enciphers the digits of values protected by check characters, and
+	// recomputes the check characters with the scheme set in Config.CheckDigit.
+	CheckDigitField
+	// ProductCodeField enciphers ISBN-10, ISBN-13, EAN-8, EAN-13, UPC-A and GTIN-14
+	// product codes into valid product codes of the same length.
+	ProductCodeField
+	// IMEIField enciphers the serial number of IMEIs and IMEISVs, keeping the Type
+	// Allocation Code.
+	IMEIField
+	// RoutingNumberField enciphers ABA routing numbers into valid routing numbers.
+	RoutingNumberField
+	// AccountNumberField enciphers US bank account numbers of 4 to 17 digits, keeping
+	// their length and separators.
+	AccountNumberField
+	// VINField enciphers Vehicle Identification Numbers into valid VINs, keeping the
+	// World Manufacturer Identifier.
+	VINField
+)
+
+const (
+	// The numeral strings handled by the fpe package are made of uint16, so the radix
+	// cannot exceed 2^16.
+	maxRadix = 1 << 16
+	minRadix = 2
+)
+
+// Config holds everything needed to build a pair of processors from a key, without
+// assembling the AES block and FPE modes by hand.
+type Config struct {
+	// Key is an AES-128, AES-192 or AES-256 key.
+	Key []byte
+	// Algorithm is the FPE mode, FF1 by default.
+	Algorithm Algorithm
+	// Tweak is the default tweak.
+	Tweak []byte
+	// Field is the type of data to encipher.
+	Field FieldType
+	// Alphabet is the list of authorized characters of a StringField.
+	Alphabet string
+	// Radix is optional. If set, it must match the size of the field alphabet.
+	Radix uint32
+	// CreditCard holds the options of a CreditCardField.
+	CreditCard CreditCardOptions
+	// String holds the options of a StringField.
+	String StringOptions
+	// Phone holds the options of a PhoneField.
+	Phone PhoneOptions
+	// Email holds the options of an EmailField.
+	Email EmailOptions
+	// Date holds the options of a DateField.
+	Date DateOptions
+	// Amount holds the options of an AmountField.
+	Amount AmountOptions
+	// IP holds the options of an IPField.
+	IP IPOptions
+	// MAC holds the options of a MACField.
+	MAC MACOptions
+	// CheckDigit holds the options of a CheckDigitField.
+	CheckDigit CheckDigitOptions
+	// ProductCode holds the options of a ProductCodeField.
+	ProductCode ProductCodeOptions
+	// RoutingNumber holds the options of a RoutingNumberField.
+	RoutingNumber RoutingNumberOptions
+	// VIN holds the options of a VINField.
+	VIN VINOptions
+}
+
+// FpeField is implemented by the processors returned by NewFpeProcessors.
+type FpeField interface {
+	// Crypt encrypts or decrypts a field value.
+	Crypt(in string) (string, error)
+}
+
+// NewFpeProcessors validates the configuration and returns an encrypter and a decrypter
+// sharing the same key and default tweak.
+func NewFpeProcessors(c Config) (enc FpeField, dec FpeField, err error) {
+	var codec, errCodec = newFieldCodec(c)
+	if errCodec != nil {
+		return nil, nil, errCodec
+	}
+	var p, errProc = newFpeProcessor(c, codec)
+	if errProc != nil {
+		return nil, nil, errProc
+	}
+	return fpeFieldCrypter{p, false}, fpeFieldCrypter{p, true}, nil
+}
+
+// fpeFieldCrypter is one direction of an fpeProcessor.
+type fpeFieldCrypter struct {
+	p       *fpeProcessor
+	decrypt bool
+}
+
+func (x fpeFieldCrypter) Crypt(in string) (string, error) {
+	if x.decrypt {
+		return x.p.Decrypt(in)
+	}
+	return x.p.Encrypt(in)
+}
+
+// newBlock checks the key length and returns the AES block expected by the algorithm.
+// FF3 and FF3-1 encipher with the byte-reversed key.
+func newBlock(alg Algorithm, key []byte) (cipher.Block, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("newBlock: invalid key length %d, must be 16, 24 or 32 bytes", len(key))
+	}
+
+	switch alg {
+	case FF1:
+		return aes.NewCipher(key)
+	case FF3, FF31:
+		return aes.NewCipher(fpe.RevB(key))
+	default:
+		return nil, fmt.Errorf("newBlock: unknown algorithm %s", alg)
+	}
+}
+
+// newMode returns a new FPE encrypter (or decrypter) for the given radix and tweak.
+func newMode(alg Algorithm, block cipher.Block, tweak []byte, radix uint32, decrypt bool) (cipher.BlockMode, error) {
+	if radix < minRadix || radix > maxRadix {
+		return nil, fmt.Errorf("newMode: radix %d out of range [%d, %d]", radix, minRadix, maxRadix)
+	}
+
+	switch alg {
+	case FF1:
+		var cbcMode = cipher.NewCBCEncrypter(block, make([]byte, aes.BlockSize))
+		if decrypt {
+			return fpe.NewFF1Decrypter(block, cbcMode, tweak, radix), nil
+		}
+		return fpe.NewFF1Encrypter(block, cbcMode, tweak, radix), nil
+	case FF3, FF31:
+		if err := checkTweak(alg, tweak); err != nil {
+			return nil, err
+		}
+		if alg == FF31 {
+			tweak = ff31Tweak(tweak)
+		}
+		if decrypt {
+			return fpe.NewFF3Decrypter(block, tweak, radix), nil
+		}
+		return fpe.NewFF3Encrypter(block, tweak, radix), nil
+	default:
+		return nil, fmt.Errorf("newMode: unknown algorithm %s", alg)
+	}
+}
+
+// ErrTweakNotSupported is returned when the tweak of a cipher.BlockMode cannot be changed.
+var ErrTweakNotSupported = errors.New("helper: BlockMode has no SetTweak function")
+
+// TweakLengthError is returned when a tweak does not have the length required by the
+// algorithm: 8 bytes for FF3 and 7 bytes for FF3-1. FF1 accepts tweaks of any length.
+type TweakLengthError struct {
+	Algorithm Algorithm
+	Length    int
+}
+
+func (e *TweakLengthError) Error() string {
+	return fmt.Sprintf("helper: invalid %s tweak length %d, must be %d bytes", e.Algorithm, e.Length, tweakLen(e.Algorithm))
+}
+
+// tweakLen returns the tweak length in bytes required by the algorithm, or -1 if any
+// length is accepted.
+func tweakLen(alg Algorithm) int {
+	switch alg {
+	case FF3:
+		return 8
+	case FF31:
+		return 7
+	default:
+		return -1
+	}
+}
+
+// checkTweak validates the tweak length required by the algorithm.
+func checkTweak(alg Algorithm, tweak []byte) error {
+	var l = tweakLen(alg)
+	if l >= 0 && len(tweak) != l {
+		return &TweakLengthError{Algorithm: alg, Length: len(tweak)}
+	}
+	return nil
+}
+
+// NumeralStringLengthError is returned when a numeral string is longer than the maximum
+// length of the algorithm for its radix. FF3 and FF3-1 encipher at most
+// 2·floor(log_radix(2^96)) numerals, e.g. 56 decimal digits (NIST SP 800-38G).
+type NumeralStringLengthError struct {
+	Algorithm Algorithm
+	Radix     uint32
+	Length    int
+}
+
+func (e *NumeralStringLengthError) Error() string {
+	return fmt.Sprintf("helper: %s numeral string of length %d exceeds the maximum length %d of radix %d", e.Algorithm, e.Length, maxNumeralStringLen(e.Algorithm, e.Radix), e.Radix)
+}
+
+// maxNumeralStringLen returns the maximum length of the numeral strings of the given radix
+// enciphered by the algorithm, or -1 if it accepts any length that fits in memory.
+func maxNumeralStringLen(alg Algorithm, radix uint32) int {
+	switch alg {
+	case FF3, FF31:
+		var limit = new(big.Int).Lsh(big.NewInt(1), 96)
+		var r = big.NewInt(int64(radix))
+		var l = 0
+		for p := new(big.Int).Set(r); p.Cmp(limit) <= 0; p.Mul(p, r) {
+			l++
+		}
+		return 2 * l
+	default:
+		return -1
+	}
+}
+
+// checkNumeralStringLen validates the maximum numeral string length of the algorithm.
+func checkNumeralStringLen(alg Algorithm, radix uint32, length int) error {
+	var l = maxNumeralStringLen(alg, radix)
+	if l >= 0 && length > l {
+		return &NumeralStringLengthError{Algorithm: alg, Radix: radix, Length: length}
+	}
+	return nil
+}
+
+// setModeTweak checks the length of the tweak of a mode of algorithm alg and changes
+// the tweak of tweaker. FF3-1 tweaks are expanded as in newMode.
+func setModeTweak(tweaker fpeWithSetTweak, alg Algorithm, tweak []byte) error {
+	if tweaker == nil {
+		return ErrTweakNotSupported
+	}
+	if err := checkTweak(alg, tweak); err != nil {
+		return err
+	}
+	if alg == FF31 {
+		tweak = ff31Tweak(tweak)
+	}
+	tweaker.SetTweak(tweak)
+	return nil
+}
+
+// ff31Tweak expands a 56-bit FF3-1 tweak T into the 64-bit FF3 tweak TL || TR, where
+// TL = T[0..27] || 0^4 and TR = T[32..55] || T[28..31] || 0^4.
+func ff31Tweak(t []byte) []byte {
+	return []byte{t[0], t[1], t[2], t[3] & 0xf0, t[4], t[5], t[6], t[3] << 4}
+}
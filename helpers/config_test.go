@@ -0,0 +1,114 @@
+package helper
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var configAlgorithms = []struct {
+	alg   Algorithm
+	tweak []byte
+}{
+	{FF1, []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a}},
+	{FF3, ff3CommonTweak1},
+	{FF31, ff3CommonTweak1[:7]},
+}
+
+func TestNewFpeProcessorsCreditCard(t *testing.T) {
+	for _, a := range configAlgorithms {
+		var enc, dec, err = NewFpeProcessors(Config{
+			Key:       commonKey128,
+			Algorithm: a.alg,
+			Tweak:     a.tweak,
+			Field:     CreditCardField,
+		})
+		if err != nil {
+			t.Errorf("%s(%s): %s", t.Name(), a.alg, err)
+			continue
+		}
+
+		for _, test := range luhnChecksumTests {
+			var ciphertext, errEnc = enc.Crypt(test.creditcardStr)
+			if errEnc != nil {
+				t.Errorf("%s(%s): %s", t.Name(), a.alg, errEnc)
+				continue
+			}
+			var plaintext, errDec = dec.Crypt(ciphertext)
+			if errDec != nil {
+				t.Errorf("%s(%s): %s", t.Name(), a.alg, errDec)
+				continue
+			}
+			if strings.Compare(plaintext, test.creditcardStr) != 0 {
+				t.Errorf("%s(%s): \nhave %s\nwant %s", t.Name(), a.alg, plaintext, test.creditcardStr)
+			}
+		}
+	}
+}
+
+func TestNewFpeProcessorsString(t *testing.T) {
+	// The FF3 processors built from a Config take the key as specified by NIST.
+	for _, test := range stringEncryptionTests {
+		var enc, dec, err = NewFpeProcessors(Config{
+			Key:       test.key,
+			Algorithm: FF3,
+			Tweak:     test.tweak,
+			Field:     StringField,
+			Alphabet:  test.alphabet,
+		})
+		if err != nil {
+			t.Errorf("%s(%s): %s", t.Name(), test.name, err)
+			continue
+		}
+
+		var out, errEnc = enc.Crypt(test.in)
+		if errEnc != nil {
+			t.Errorf("%s(%s): %s", t.Name(), test.name, errEnc)
+			continue
+		}
+		if strings.Compare(out, test.out) != 0 {
+			t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), test.name, out, test.out)
+		}
+		var in, errDec = dec.Crypt(out)
+		if errDec != nil {
+			t.Errorf("%s(%s): %s", t.Name(), test.name, errDec)
+			continue
+		}
+		if strings.Compare(in, test.in) != 0 {
+			t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), test.name, in, test.in)
+		}
+	}
+}
+
+func TestNewFpeProcessorsInvalidConfig(t *testing.T) {
+	var configs = []struct {
+		name string
+		c    Config
+	}{
+		{"empty key", Config{Field: CreditCardField}},
+		{"short key", Config{Key: commonKey128[:15], Field: CreditCardField}},
+		{"unknown algorithm", Config{Key: commonKey128, Algorithm: Algorithm(42), Field: CreditCardField}},
+		{"unknown field", Config{Key: commonKey128, Field: FieldType(42)}},
+		{"radix mismatch", Config{Key: commonKey128, Field: StringField, Alphabet: "0123456789", Radix: 16}},
+		{"credit card radix", Config{Key: commonKey128, Field: CreditCardField, Radix: 16}},
+		{"empty alphabet", Config{Key: commonKey128, Field: StringField}},
+		{"FF3-1 tweak", Config{Key: commonKey128, Algorithm: FF31, Tweak: ff3CommonTweak1, Field: CreditCardField}},
+	}
+
+	for _, test := range configs {
+		var _, _, err = NewFpeProcessors(test.c)
+		if err == nil {
+			t.Errorf("%s(%s): NewFpeProcessors should fail", t.Name(), test.name)
+		}
+	}
+}
+
+func TestFF31Tweak(t *testing.T) {
+	var tweak = []byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde}
+	var expected = []byte{0x12, 0x34, 0x56, 0x70, 0x9a, 0xbc, 0xde, 0x80}
+
+	var expanded = ff31Tweak(tweak)
+	if !bytes.Equal(expanded, expected) {
+		t.Errorf("%s:\nhave %x\nwant %x", t.Name(), expanded, expected)
+	}
+}
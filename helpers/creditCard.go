@@ -5,6 +5,8 @@ package helper
 import (
 	"crypto/cipher"
 	"crypto-fpe/fpe"
+	"errors"
+	"fmt"
 )
 
 const(
@@ -13,11 +15,64 @@ const(
 	// A CC length is between 13 and 19 digits
 	ccMinLen = 13
 	ccMaxLen = 19
+	// Number of digits left in clear at the end of the number by the truncation modes
+	ccLastLen = 4
 )
 
+var (
+	// ErrNoDigits is returned when the credit card number contains no digit.
+	ErrNoDigits = errors.New("helper: credit card number has no digits")
+	// ErrTooShort is returned when the credit card number has less than 13 digits.
+	ErrTooShort = errors.New("helper: credit card number is too short")
+	// ErrTooLong is returned when the credit card number has more than 19 digits.
+	ErrTooLong = errors.New("helper: credit card number is too long")
+	// ErrBadLuhn is returned when the check digit of the credit card number is invalid.
+	ErrBadLuhn = errors.New("helper: credit card number has an invalid Luhn check digit")
+)
+
+// LuhnPolicy tells how credit card numbers with an invalid check digit are handled.
+type LuhnPolicy int
+
+const (
+	// RejectInvalidLuhn makes Crypt return ErrBadLuhn.
+	RejectInvalidLuhn LuhnPolicy = iota
+	// PassInvalidLuhn enciphers the number anyway. The ciphertext has the same Luhn sum
+	// as the input, so that it is invalid too and deciphers back to the original input.
+	PassInvalidLuhn
+)
+
+// Truncation tells which digits of a credit card number are left in clear.
+type Truncation int
+
+const (
+	// KeepCheckDigit only leaves the check digit in clear, it is recomputed from the
+	// enciphered digits.
+	KeepCheckDigit Truncation = iota
+	// KeepBIN6Last4 leaves the 6-digit IIN and the last 4 digits in clear, as in PCI DSS
+	// truncation.
+	KeepBIN6Last4
+	// KeepBIN8Last4 leaves the 8-digit BIN and the last 4 digits in clear.
+	KeepBIN8Last4
+)
+
+// CreditCardOptions configures the processors of a CreditCardField.
+type CreditCardOptions struct {
+	// Luhn is the policy for numbers with an invalid check digit.
+	Luhn LuhnPolicy
+	// Truncation selects the digits left in clear. With KeepBIN6Last4 and KeepBIN8Last4,
+	// the digit before the last 4 is recomputed to keep the Luhn checksum valid, and at
+	// least 2 digits must remain to encipher: numbers need 13 digits with a 6-digit IIN
+	// and 15 digits with an 8-digit BIN, otherwise Crypt returns ErrTooShort.
+	Truncation Truncation
+}
+
 type FpeCreditCard interface {
 	// Crypt encrypts or decrypts a credit card number.
 	Crypt(src string) (string, error)
+	// SetTweak changes the tweak of the underlying mode. It returns ErrTweakNotSupported
+	// if the mode has no SetTweak function, and a *TweakLengthError if the processor knows
+	// the algorithm of the mode and the tweak length is invalid.
+	SetTweak(tweak []byte) error
 }
 
 type fpeWithSetTweak interface {
@@ -27,46 +82,118 @@ type fpeWithSetTweak interface {
 
 type ccFpe struct {
 	m	cipher.BlockMode
+	// tweaker is nil if the mode cannot be tweaked.
+	tweaker	fpeWithSetTweak
+	// alg is unknownAlgorithm if the processor was not given the algorithm of m.
+	alg	Algorithm
+	opts	CreditCardOptions
 }
 
 func newFPECreditCard(m cipher.BlockMode) *ccFpe {
+	var tweaker, _ = m.(fpeWithSetTweak)
 	return &ccFpe{
-		m:	m,
+		m:		m,
+		tweaker:	tweaker,
+		alg:		unknownAlgorithm,
 	}
 }
 
 type fpeCreditCardProcessor ccFpe
 
+// NewFPECreditCardProcessor returns a credit card processor using the mode m. Numbers
+// with an invalid check digit are enciphered as with PassInvalidLuhn, use
+// NewFPECreditCardProcessorWithOptions to reject them. The algorithm of m is unknown, so
+// SetTweak cannot check tweak lengths.
 func NewFPECreditCardProcessor(m cipher.BlockMode) FpeCreditCard {
-	return (*fpeCreditCardProcessor)(newFPECreditCard(m))
+	return NewFPECreditCardProcessorWithOptions(m, CreditCardOptions{Luhn: PassInvalidLuhn})
+}
+
+// NewFPECreditCardProcessorWithOptions returns a credit card processor using the mode m,
+// configured as the processors of a CreditCardField. SetTweak cannot check tweak lengths,
+// use NewFPECreditCardProcessorWithAlgorithm for that.
+func NewFPECreditCardProcessorWithOptions(m cipher.BlockMode, opts CreditCardOptions) FpeCreditCard {
+	var x = newFPECreditCard(m)
+	x.opts = opts
+	return (*fpeCreditCardProcessor)(x)
+}
+
+// NewFPECreditCardProcessorWithAlgorithm is NewFPECreditCardProcessorWithOptions for a mode
+// m of algorithm alg, whose SetTweak rejects tweaks of invalid length. For FF31, m is an
+// FF3 mode and SetTweak takes 7-byte FF3-1 tweaks.
+func NewFPECreditCardProcessorWithAlgorithm(m cipher.BlockMode, alg Algorithm, opts CreditCardOptions) (FpeCreditCard, error) {
+	if !validAlgorithm(alg) {
+		return nil, fmt.Errorf("NewFPECreditCardProcessorWithAlgorithm: unknown algorithm %s", alg)
+	}
+	var x = newFPECreditCard(m)
+	x.alg = alg
+	x.opts = opts
+	return (*fpeCreditCardProcessor)(x), nil
 }
 
 func (x *fpeCreditCardProcessor) Crypt(in string) (string, error) {
+	return creditCardCodec{x.opts}.crypt(fixedMode(x.m), in)
+}
+
+// creditCardCodec enciphers the digits of a credit card number, except the check digit.
+type creditCardCodec struct {
+	opts CreditCardOptions
+}
+
+func (c creditCardCodec) crypt(newMode modeFunc, in string) (string, error) {
 	var runes = []rune(in)
-	var numeralString = make([]uint16, ccMaxLen)
-	var numStrIdx = 0
+	var numeralString = make([]uint16, 0, ccMaxLen)
 
 	// Create numeral string
 	for _, r := range runes {
 		// We only take digits and leave eventual separators char like '-', ' '
 		if r >= 48 && r <= 57 {
-			numeralString[numStrIdx] = uint16(r) - 48
-			numStrIdx++
+			if len(numeralString) == ccMaxLen {
+				return "", ErrTooLong
+			}
+			numeralString = append(numeralString, uint16(r) - 48)
 		}
 	}
 
-	// We remove unused trailing digits (needed because CC length is not fix) and the checksum
-	numeralString = numeralString[:numStrIdx-1]
+	switch {
+	case len(numeralString) == 0:
+		return "", ErrNoDigits
+	case len(numeralString) < ccMinLen:
+		return "", ErrTooShort
+	}
+
+	// The Luhn sum is zero for a valid number, the ciphertext keeps the same sum
+	var sum = luhnSum(numeralString)
+	if sum != 0 && c.opts.Luhn != PassInvalidLuhn {
+		return "", ErrBadLuhn
+	}
+
+	// The digits in [first, fix) are enciphered and the digit at index fix is recomputed
+	// to keep the Luhn sum. It is the check digit unless the last 4 digits are kept.
+	var first, fix = 0, len(numeralString) - 1
+	switch c.opts.Truncation {
+	case KeepBIN6Last4:
+		first, fix = 6, len(numeralString) - ccLastLen - 1
+	case KeepBIN8Last4:
+		first, fix = 8, len(numeralString) - ccLastLen - 1
+	}
+	if fix - first < minDecimalLen {
+		return "", ErrTooShort
+	}
+
+	var m, err = newMode(CCRadix, fix-first)
+	if err != nil {
+		return "", err
+	}
 
 	// Encrypt numeral string
-	var b = fpe.NumeralStringToBytes(numeralString)
-	x.m.CryptBlocks(b, b)
-	numeralString = fpe.BytesToNumeralString(b)
+	var b = fpe.NumeralStringToBytes(numeralString[first:fix])
+	m.CryptBlocks(b, b)
+	copy(numeralString[first:fix], fpe.BytesToNumeralString(b))
 
 	// Compute ciphertext Luhn checksum
-	numeralString = append(numeralString, luhnChecksum(numeralString))
+	setLuhnDigit(numeralString, fix, sum)
 
-	numStrIdx = 0
+	var numStrIdx = 0
 	// Copy enciphered data back to runes
 	for i, r := range runes {
 		// We replace clear text digits with enciphered ones, while preserving eventual separators
@@ -80,12 +207,8 @@ func (x *fpeCreditCardProcessor) Crypt(in string) (string, error) {
 	return string(runes), nil
 }
 
-func (x *fpeCreditCardProcessor) SetTweak(tweak []byte) {
-	var fpeModeWithSetTweak, ok = x.m.(fpeWithSetTweak)
-	if !ok {
-		panic("fpeCreditCardProcessor/SetTweak: BlockMode must have a SetTweak function.")
-	}
-	fpeModeWithSetTweak.SetTweak(tweak)
+func (x *fpeCreditCardProcessor) SetTweak(tweak []byte) error {
+	return setModeTweak(x.tweaker, x.alg, tweak)
 }
 
 // We compute the Luhn Checksum over the numeral string.
@@ -105,14 +228,25 @@ func luhnChecksum(numeralString []uint16) (uint16) {
 	return checksum
 }
 
-func validateChecksum(numeralString []uint16) (bool) {
-	var creditCardNbr = numeralString[:len(numeralString)-1]
-	var checksum = numeralString[len(numeralString)-1]
-	var computedChecksum = luhnChecksum(creditCardNbr)
-
-	if computedChecksum == checksum {
-		return true
-	} else {
-		return false
+// luhnSum computes the Luhn sum modulo 10 of a numeral string ending with its check digit.
+// It is zero if the check digit is valid.
+func luhnSum(numeralString []uint16) uint16 {
+	var l = len(numeralString)
+	return (numeralString[l-1] + 10 - luhnChecksum(numeralString[:l-1])) % 10
+}
+
+// setLuhnDigit sets the digit at index i of the numeral string so that its Luhn sum is sum.
+// Each position contributes a permutation of the digits to the sum, so there is exactly
+// one such digit.
+func setLuhnDigit(numeralString []uint16, i int, sum uint16) {
+	for d := uint16(0); d < CCRadix; d++ {
+		numeralString[i] = d
+		if luhnSum(numeralString) == sum {
+			return
+		}
 	}
+}
+
+func validateChecksum(numeralString []uint16) (bool) {
+	return Luhn.Validate(numeralStringToDecimal(numeralString))
 }
\ No newline at end of file
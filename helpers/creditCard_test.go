@@ -216,7 +216,7 @@ func TestValidateLuhnChecksum(t *testing.T) {
 func TestSetTweak(t *testing.T) {
 	type fpeCCWithTweak interface {
 		Crypt(src string) (string, error)
-		SetTweak([]byte)
+		SetTweak([]byte) error
 	}
 	var key = make([]byte, 16)
 	// Get two different tweaks
@@ -262,6 +262,64 @@ func TestSetTweak(t *testing.T) {
 	ff1DecTweak.SetTweak(otherTweak)
 }
 
+func TestSetTweakWithAlgorithm(t *testing.T) {
+	var cc, alphabet = "5503059576140641", "0123456789abcdefghijklmnopqrstuvwxyz"
+	for _, a := range configAlgorithms {
+		var block, err = newBlock(a.alg, commonKey128)
+		if err != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+		var ccMode, errCCMode = newMode(a.alg, block, a.tweak, CCRadix, false)
+		var strMode, errStrMode = newMode(a.alg, block, a.tweak, uint32(len(alphabet)), false)
+		if errCCMode != nil || errStrMode != nil {
+			t.Fatalf("%s(%s): %v %v", t.Name(), a.alg, errCCMode, errStrMode)
+		}
+		var ccProcessor, errCC = NewFPECreditCardProcessorWithAlgorithm(ccMode, a.alg, CreditCardOptions{})
+		var strProcessor, errStr = NewFpeStringProcessorWithAlgorithm(strMode, a.alg, alphabet, StringOptions{})
+		if errCC != nil || errStr != nil {
+			t.Fatalf("%s(%s): %v %v", t.Name(), a.alg, errCC, errStr)
+		}
+
+		// Tweaks of invalid length are rejected for FF3 and FF3-1
+		var short = []byte{0x01, 0x02, 0x03}
+		for _, errTweak := range []error{ccProcessor.SetTweak(short), strProcessor.SetTweak(short)} {
+			var _, isLengthError = errTweak.(*TweakLengthError)
+			if isLengthError != (a.alg != FF1) {
+				t.Errorf("%s(%s): SetTweak(%x) = %v", t.Name(), a.alg, short, errTweak)
+			}
+		}
+
+		// Setting back the tweak gives the ciphertexts of the Config processors
+		if err := ccProcessor.SetTweak(a.tweak); err != nil {
+			t.Errorf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+		if err := strProcessor.SetTweak(a.tweak); err != nil {
+			t.Errorf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+		var ccConfig, _ = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: CreditCardField})
+		var strConfig, _ = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: StringField, Alphabet: alphabet})
+		var ccEnc, errCCEnc = ccProcessor.Crypt(cc)
+		var ccWant, errCCWant = ccConfig.Encrypt(cc)
+		if errCCEnc != nil || errCCWant != nil || ccEnc != ccWant {
+			t.Errorf("%s(%s):\nhave %s %v\nwant %s %v", t.Name(), a.alg, ccEnc, errCCEnc, ccWant, errCCWant)
+		}
+		var strEnc, errStrEnc = strProcessor.Crypt("helloworld")
+		var strWant, errStrWant = strConfig.Encrypt("helloworld")
+		if errStrEnc != nil || errStrWant != nil || strEnc != strWant {
+			t.Errorf("%s(%s):\nhave %s %v\nwant %s %v", t.Name(), a.alg, strEnc, errStrEnc, strWant, errStrWant)
+		}
+	}
+
+	var aesBlock, _ = aes.NewCipher(commonKey128)
+	var m = fpe.NewFF3Encrypter(aesBlock, commonTweak, CCRadix)
+	if _, err := NewFPECreditCardProcessorWithAlgorithm(m, Algorithm(42), CreditCardOptions{}); err == nil {
+		t.Errorf("%s: unknown algorithm accepted", t.Name())
+	}
+	if _, err := NewFpeStringProcessorWithAlgorithm(m, Algorithm(42), "0123456789", StringOptions{}); err == nil {
+		t.Errorf("%s: unknown algorithm accepted", t.Name())
+	}
+}
+
 func TestCreditCardSeparators(t *testing.T) {
 	var key = make([]byte, 16)
 	rand.Read(key)
@@ -311,3 +369,177 @@ func TestCreditCardSeparators(t *testing.T) {
 }
 
 
+func TestSetTweakNotSupported(t *testing.T) {
+	var aesBlock, err = aes.NewCipher(commonKey128)
+	if err != nil {
+		t.Fatalf("%s: NewCipher = %s", t.Name(), err)
+	}
+
+	// A CBC mode has no SetTweak function
+	var cbcMode = cipher.NewCBCEncrypter(aesBlock, make([]byte, 16))
+
+	var ccProcessor = NewFPECreditCardProcessor(cbcMode)
+	if errTweak := ccProcessor.SetTweak(commonTweak); errTweak != ErrTweakNotSupported {
+		t.Errorf("%s:\nhave %v\nwant %v", t.Name(), errTweak, ErrTweakNotSupported)
+	}
+	var strProcessor = NewFpeStringProcessor(cbcMode, "0123456789")
+	if errTweak := strProcessor.SetTweak(commonTweak); errTweak != ErrTweakNotSupported {
+		t.Errorf("%s:\nhave %v\nwant %v", t.Name(), errTweak, ErrTweakNotSupported)
+	}
+}
+
+var creditCardValidationTests = []struct {
+	name string
+	in   string
+	err  error
+}{
+	{"empty", "", ErrNoDigits},
+	{"no digits", "abcd-efgh", ErrNoDigits},
+	{"12 digits", "4485 9319 0756", ErrTooShort},
+	{"20 digits", "4556 6109 2569 6214 0781", ErrTooLong},
+	{"30 digits", "455661092569621407814556610925", ErrTooLong},
+	{"bad luhn", "5503 0595 7614 0642", ErrBadLuhn},
+	{"valid", "5503 0595 7614 0641", nil},
+}
+
+func TestCreditCardValidation(t *testing.T) {
+	var aesBlock, err = aes.NewCipher(commonKey128)
+	if err != nil {
+		t.Fatalf("%s: NewCipher = %s", t.Name(), err)
+	}
+	var creditCardEncrypter = NewFPECreditCardProcessorWithOptions(fpe.NewFF3Encrypter(aesBlock, commonTweak, CCRadix), CreditCardOptions{})
+
+	for _, test := range creditCardValidationTests {
+		var _, errEnc = creditCardEncrypter.Crypt(test.in)
+		if errEnc != test.err {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), test.name, errEnc, test.err)
+		}
+	}
+}
+
+func TestCreditCardLegacyInvalidLuhn(t *testing.T) {
+	var aesBlock, err = aes.NewCipher(commonKey128)
+	if err != nil {
+		t.Fatalf("%s: NewCipher = %s", t.Name(), err)
+	}
+	var creditCardEncrypter = NewFPECreditCardProcessor(fpe.NewFF3Encrypter(aesBlock, commonTweak, CCRadix))
+	var creditCardDecrypter = NewFPECreditCardProcessor(fpe.NewFF3Decrypter(aesBlock, commonTweak, CCRadix))
+
+	// The processors of NewFPECreditCardProcessor encipher numbers with an invalid check digit
+	var cc = "5503 0595 7614 0642"
+	var enc, errEnc = creditCardEncrypter.Crypt(cc)
+	if errEnc != nil {
+		t.Fatalf("%s: %s", t.Name(), errEnc)
+	}
+	var dec, errDec = creditCardDecrypter.Crypt(enc)
+	if errDec != nil {
+		t.Fatalf("%s: %s", t.Name(), errDec)
+	}
+	if strings.Compare(dec, cc) != 0 {
+		t.Errorf("%s:\nhave %s\nwant %s", t.Name(), dec, cc)
+	}
+}
+
+func TestCreditCardPassInvalidLuhn(t *testing.T) {
+	var p, err = NewFpeProcessor(Config{
+		Key:        commonKey128,
+		Algorithm:  FF3,
+		Tweak:      commonTweak,
+		Field:      CreditCardField,
+		CreditCard: CreditCardOptions{Luhn: PassInvalidLuhn},
+	})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	for _, cc := range []string{"5503 0595 7614 0642", "4485931907560", "5503059576140641"} {
+		var enc, errEnc = p.Encrypt(cc)
+		if errEnc != nil {
+			t.Errorf("%s: %s", t.Name(), errEnc)
+			continue
+		}
+		var dec, errDec = p.Decrypt(enc)
+		if errDec != nil {
+			t.Errorf("%s: %s", t.Name(), errDec)
+			continue
+		}
+		if strings.Compare(dec, cc) != 0 {
+			t.Errorf("%s: \nhave %s\nwant %s", t.Name(), dec, cc)
+		}
+	}
+}
+
+func TestCreditCardTruncation(t *testing.T) {
+	var truncations = []struct {
+		truncation Truncation
+		first      int
+	}{
+		{KeepBIN6Last4, 6},
+		{KeepBIN8Last4, 8},
+	}
+
+	for _, tr := range truncations {
+		var p, err = NewFpeProcessor(Config{
+			Key:        commonKey128,
+			Algorithm:  FF3,
+			Tweak:      commonTweak,
+			Field:      CreditCardField,
+			CreditCard: CreditCardOptions{Truncation: tr.truncation},
+		})
+		if err != nil {
+			t.Fatalf("%s: %s", t.Name(), err)
+		}
+
+		for _, test := range luhnChecksumTests {
+			var l = len(test.creditcardStr)
+			var enc, errEnc = p.Encrypt(test.creditcardStr)
+			if l-tr.first-ccLastLen-1 < minDecimalLen {
+				if errEnc != ErrTooShort {
+					t.Errorf("%s(%d):\nhave %v\nwant %v", t.Name(), tr.first, errEnc, ErrTooShort)
+				}
+				continue
+			}
+			if errEnc != nil {
+				t.Errorf("%s(%d): %s", t.Name(), tr.first, errEnc)
+				continue
+			}
+
+			// BIN and last 4 digits are kept, the ciphertext has a valid Luhn checksum
+			if enc[:tr.first] != test.creditcardStr[:tr.first] || enc[l-ccLastLen:] != test.creditcardStr[l-ccLastLen:] {
+				t.Errorf("%s(%d): %s does not keep the BIN and last 4 digits of %s", t.Name(), tr.first, enc, test.creditcardStr)
+			}
+			var numeralString = make([]uint16, l)
+			for i := range enc {
+				numeralString[i] = uint16(enc[i] - '0')
+			}
+			if !validateChecksum(numeralString) {
+				t.Errorf("%s(%d): %s has an invalid Luhn checksum", t.Name(), tr.first, enc)
+			}
+
+			var dec, errDec = p.Decrypt(enc)
+			if errDec != nil {
+				t.Errorf("%s(%d): %s", t.Name(), tr.first, errDec)
+				continue
+			}
+			if strings.Compare(dec, test.creditcardStr) != 0 {
+				t.Errorf("%s(%d): \nhave %s\nwant %s", t.Name(), tr.first, dec, test.creditcardStr)
+			}
+		}
+	}
+}
+
+func TestSetLuhnDigit(t *testing.T) {
+	for _, test := range luhnChecksumTests {
+		var l = len(test.creditcard)
+		for i := 0; i < l; i++ {
+			var numeralString = make([]uint16, l)
+			copy(numeralString, test.creditcard)
+			numeralString[i] = (numeralString[i] + 3) % 10
+
+			setLuhnDigit(numeralString, i, 0)
+			if numeralString[i] != test.creditcard[i] {
+				t.Errorf("%s(%s, %d):\nhave %d\nwant %d", t.Name(), test.creditcardStr, i, numeralString[i], test.creditcard[i])
+			}
+		}
+	}
+}
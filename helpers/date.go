@@ -0,0 +1,84 @@
+// A date is written in a Go time layout, e.g. "2006-01-02" or "02/01/2006". It is handled
+// as the number of days since 1970-01-01, enciphered within the configured date range.
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	// ISO 8601 calendar date
+	defaultDateLayout = "2006-01-02"
+	secondsPerDay     = 24 * 60 * 60
+)
+
+// ErrDateOutOfRange is returned when a date is before DateOptions.Min or after
+// DateOptions.Max.
+var ErrDateOutOfRange = errors.New("helper: date out of range")
+
+// DateOptions configures the processors of a DateField.
+type DateOptions struct {
+	// Layout is the Go time layout of the dates, "2006-01-02" by default.
+	Layout string
+	// Min and Max are the first and last days of the range of the dates, inclusive.
+	// The range must hold at least 100 days.
+	Min, Max time.Time
+}
+
+// dateCodec enciphers dates into dates of the same range and layout. The time of day and
+// the time zone, if any in the layout, are left unchanged.
+type dateCodec struct {
+	layout string
+	days   *intRange
+}
+
+// newDateCodec checks the date range and returns a date codec.
+func newDateCodec(opts DateOptions) (*dateCodec, error) {
+	var layout = opts.Layout
+	if layout == "" {
+		layout = defaultDateLayout
+	}
+
+	var min, max = dayNumber(opts.Min), dayNumber(opts.Max)
+	if max < min {
+		return nil, fmt.Errorf("newDateCodec: max date %s before min date %s",
+			opts.Max.Format(defaultDateLayout), opts.Min.Format(defaultDateLayout))
+	}
+	var days, err = newIntRange(big.NewInt(min), big.NewInt(max))
+	if err != nil {
+		return nil, err
+	}
+
+	return &dateCodec{
+		layout: layout,
+		days:   days,
+	}, nil
+}
+
+func (c *dateCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var t, err = time.Parse(c.layout, in)
+	if err != nil {
+		return "", err
+	}
+
+	var dayNum, errCrypt = c.days.crypt(newMode, big.NewInt(dayNumber(t)))
+	if errCrypt == ErrIntOutOfRange {
+		return "", ErrDateOutOfRange
+	} else if errCrypt != nil {
+		return "", errCrypt
+	}
+
+	var year, month, day = time.Unix(dayNum.Int64()*secondsPerDay, 0).UTC().Date()
+	var out = time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	return out.Format(c.layout), nil
+}
+
+// dayNumber returns the number of days between 1970-01-01 and the date of t, in the
+// location of t.
+func dayNumber(t time.Time) int64 {
+	var year, month, day = t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / secondsPerDay
+}
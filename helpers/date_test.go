@@ -0,0 +1,100 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var birthDates = DateOptions{
+	Min: time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC),
+	Max: time.Date(2020, time.December, 31, 0, 0, 0, 0, time.UTC),
+}
+
+func TestDateEncryptDecrypt(t *testing.T) {
+	var dateTests = []struct {
+		layout string
+		dates  []string
+	}{
+		{"", []string{"1900-01-01", "1975-06-30", "2000-02-29", "2020-12-31"}},
+		{"02/01/2006", []string{"01/01/1900", "14/07/1989", "31/12/2020"}},
+		{"Jan 2, 2006", []string{"Mar 4, 1955", "Dec 31, 2020"}},
+		{"2006-01-02T15:04:05Z07:00", []string{"1980-03-01T08:30:00+01:00", "2010-10-10T23:59:59Z"}},
+	}
+
+	for _, a := range configAlgorithms {
+		for _, test := range dateTests {
+			var opts = birthDates
+			opts.Layout = test.layout
+			var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: DateField, Date: opts})
+			if err != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+			}
+
+			var layout = test.layout
+			if layout == "" {
+				layout = defaultDateLayout
+			}
+			for _, date := range test.dates {
+				var enc, errEnc = p.Encrypt(date)
+				if errEnc != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, date, errEnc)
+					continue
+				}
+
+				// The ciphertext is a date of the range, with the time of day of the plaintext
+				var in, _ = time.Parse(layout, date)
+				var out, errParse = time.Parse(layout, enc)
+				if errParse != nil {
+					t.Errorf("%s(%s): %s", t.Name(), a.alg, errParse)
+					continue
+				}
+				if out.Before(opts.Min) || out.After(opts.Max.AddDate(0, 0, 1)) {
+					t.Errorf("%s(%s): %s out of range", t.Name(), a.alg, enc)
+				}
+				if strings.Compare(out.Format("15:04:05Z07:00"), in.Format("15:04:05Z07:00")) != 0 {
+					t.Errorf("%s(%s): %s does not keep the time of %s", t.Name(), a.alg, enc, date)
+				}
+
+				var dec, errDec = p.Decrypt(enc)
+				if errDec != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, date, errDec)
+					continue
+				}
+				if strings.Compare(dec, date) != 0 {
+					t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, date)
+				}
+			}
+		}
+	}
+}
+
+func TestDateErrors(t *testing.T) {
+	var p, err = NewFpeProcessor(Config{Key: commonKey128, Field: DateField, Date: birthDates})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	for _, date := range []string{"1899-12-31", "2021-01-01"} {
+		if _, errEnc := p.Encrypt(date); errEnc != ErrDateOutOfRange {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), date, errEnc, ErrDateOutOfRange)
+		}
+	}
+	for _, date := range []string{"", "1980-02-30", "01/02/1980"} {
+		if _, errEnc := p.Encrypt(date); errEnc == nil {
+			t.Errorf("%s: %s should be rejected", t.Name(), date)
+		}
+	}
+
+	// Invalid ranges
+	var day = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if _, err = NewFpeProcessor(Config{Key: commonKey128, Field: DateField, Date: DateOptions{Min: day, Max: day.AddDate(0, 0, 98)}}); err != ErrDomainTooSmall {
+		t.Errorf("%s:\nhave %v\nwant %v", t.Name(), err, ErrDomainTooSmall)
+	}
+	if _, err = NewFpeProcessor(Config{Key: commonKey128, Field: DateField, Date: DateOptions{Min: day, Max: day.AddDate(0, 0, 99)}}); err != nil {
+		t.Errorf("%s: %s", t.Name(), err)
+	}
+	if _, err = NewFpeProcessor(Config{Key: commonKey128, Field: DateField, Date: DateOptions{Min: day, Max: day.AddDate(0, 0, -1)}}); err == nil {
+		t.Errorf("%s: max before min should be rejected", t.Name())
+	}
+}
@@ -0,0 +1,160 @@
+package helper
+
+import (
+	"crypto/cipher"
+	"crypto-fpe/fpe"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const (
+	// Integers are enciphered as decimal numeral strings
+	decimalRadix = 10
+	// minDomainSize is the minimum number of values of an enciphered domain: FF1 and FF3
+	// require radix^minlen >= 100 (NIST SP 800-38G).
+	//
+	// NIST SP 800-38G Rev. 1 raises the minimum domain size of FF1 and FF3-1 to 10^6. It
+	// is not enforced, because common formats have smaller domains by design: the middle
+	// digits of a 16-digit card truncated to its BIN6 and last 4 (10^5 values), the host
+	// part of an IPv4 /24 network (256 values) or a window of birth dates (about 44,000
+	// values). Values of small domains can be recovered by exhaustive search, so callers
+	// requiring Rev. 1 compliance must choose options leaving at least 10^6 values.
+	minDomainSize = 100
+	// minDecimalLen is the number of decimal digits of the smallest domain
+	minDecimalLen = 2
+)
+
+// ErrDomainTooSmall is returned when a value has less than 100 possible values, which is
+// below the minimum domain size of FF1 and FF3.
+var ErrDomainTooSmall = errors.New("helper: domain too small for format-preserving encryption")
+
+// cryptInt enciphers (or deciphers, depending on newMode) x in [0, n) into [0, n). x is
+// written as a decimal numeral string long enough to hold n-1 and enciphered until the
+// result falls in [0, n) (cycle-walking).
+func cryptInt(newMode modeFunc, n, x *big.Int) (*big.Int, error) {
+	if n.Cmp(big.NewInt(minDomainSize)) < 0 {
+		return nil, ErrDomainTooSmall
+	}
+	if x.Sign() < 0 || x.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("cryptInt: %s not in range [0, %s)", x, n)
+	}
+
+	var l = len(new(big.Int).Sub(n, big.NewInt(1)).String())
+	var m, err = newMode(decimalRadix, l)
+	if err != nil {
+		return nil, err
+	}
+	var numeralString = cycleWalk(m, intToNumeralString(x, decimalRadix, l), func(numeralString []uint16) bool {
+		return numeralStringToInt(numeralString, decimalRadix).Cmp(n) < 0
+	})
+	return numeralStringToInt(numeralString, decimalRadix), nil
+}
+
+// cycleWalk enciphers (or deciphers) a valid numeral string with m until the result is
+// valid. Since m is a permutation, this is a permutation of the valid numeral strings.
+func cycleWalk(m cipher.BlockMode, numeralString []uint16, valid func([]uint16) bool) []uint16 {
+	var b = fpe.NumeralStringToBytes(numeralString)
+	for {
+		m.CryptBlocks(b, b)
+		numeralString = fpe.BytesToNumeralString(b)
+		if valid(numeralString) {
+			return numeralString
+		}
+	}
+}
+
+// cryptDigits enciphers (or deciphers) the decimal digits of runes from the first to the
+// end one (excluded) as a numeral string, cycle-walked until valid accepts it if valid is
+// not nil. The other characters are left unchanged.
+func cryptDigits(newMode modeFunc, runes []rune, first, end int, valid func([]uint16) bool) error {
+	var positions = make([]int, 0, len(runes))
+	for i, r := range runes {
+		if r >= '0' && r <= '9' {
+			positions = append(positions, i)
+		}
+	}
+	return cryptDecimalPositions(newMode, runes, positions[first:end], valid)
+}
+
+// cryptDecimalPositions enciphers (or deciphers) the decimal digits of runes at the given
+// positions as a numeral string, cycle-walked until valid accepts it if valid is not nil.
+// It returns ErrDomainTooSmall if there are less than minDecimalLen digits.
+func cryptDecimalPositions(newMode modeFunc, runes []rune, positions []int, valid func([]uint16) bool) error {
+	if len(positions) < minDecimalLen {
+		return ErrDomainTooSmall
+	}
+
+	// Create numeral string
+	var numeralString = make([]uint16, len(positions))
+	for numStrIdx, i := range positions {
+		numeralString[numStrIdx] = uint16(runes[i] - '0')
+	}
+
+	var m, err = newMode(decimalRadix, len(numeralString))
+	if err != nil {
+		return err
+	}
+	if valid == nil {
+		valid = func([]uint16) bool { return true }
+	}
+	numeralString = cycleWalk(m, numeralString, valid)
+
+	// Copy enciphered data back to runes
+	for numStrIdx, i := range positions {
+		runes[i] = rune('0' + numeralString[numStrIdx])
+	}
+	return nil
+}
+
+// cryptMixedRadix enciphers (or deciphers) the characters of runes at the given positions
+// as a single mixed-radix number, where the character at positions[k] is taken from
+// alphabets[k]. The other characters are left unchanged.
+func cryptMixedRadix(newMode modeFunc, runes []rune, positions []int, alphabets []*stringCodec) error {
+	var n = big.NewInt(1)
+	var x = big.NewInt(0)
+	for k, i := range positions {
+		var num, ok = alphabets[k].alphabetMap[runes[i]]
+		if !ok {
+			return fmt.Errorf("cryptMixedRadix: Character %q at index %d not in alphabet", runes[i], i)
+		}
+		var radix = big.NewInt(int64(len(alphabets[k].alphabetSlice)))
+		x.Mul(x, radix).Add(x, big.NewInt(int64(num)))
+		n.Mul(n, radix)
+	}
+
+	var y, err = cryptInt(newMode, n, x)
+	if err != nil {
+		return err
+	}
+
+	var num = new(big.Int)
+	for k := len(positions) - 1; k >= 0; k-- {
+		y.DivMod(y, big.NewInt(int64(len(alphabets[k].alphabetSlice))), num)
+		runes[positions[k]] = alphabets[k].alphabetSlice[num.Int64()]
+	}
+	return nil
+}
+
+// intToNumeralString writes x in the given radix with exactly l numerals.
+func intToNumeralString(x *big.Int, radix uint32, l int) []uint16 {
+	var numeralString = make([]uint16, l)
+	var r = big.NewInt(int64(radix))
+	var y = new(big.Int).Set(x)
+	var num = new(big.Int)
+	for i := l - 1; i >= 0; i-- {
+		y.DivMod(y, r, num)
+		numeralString[i] = uint16(num.Int64())
+	}
+	return numeralString
+}
+
+// numeralStringToInt returns the integer written by the numeral string in the given radix.
+func numeralStringToInt(numeralString []uint16, radix uint32) *big.Int {
+	var x = new(big.Int)
+	var r = big.NewInt(int64(radix))
+	for _, num := range numeralString {
+		x.Mul(x, r).Add(x, big.NewInt(int64(num)))
+	}
+	return x
+}
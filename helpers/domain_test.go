@@ -0,0 +1,106 @@
+package helper
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCryptIntPermutation(t *testing.T) {
+	for _, a := range configAlgorithms {
+		var p, err = newFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak}, nil)
+		if err != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+		var enc, dec = p.modes(p.tweak, false), p.modes(p.tweak, true)
+
+		// Every value of the domain is mapped to a distinct value of the domain
+		for _, n := range []int64{100, 137, 1000, 1999} {
+			var seen = make(map[int64]bool)
+			for x := int64(0); x < n; x++ {
+				var y, errEnc = cryptInt(enc, big.NewInt(n), big.NewInt(x))
+				if errEnc != nil {
+					t.Fatalf("%s(%s): %s", t.Name(), a.alg, errEnc)
+				}
+				if y.Sign() < 0 || y.Int64() >= n || seen[y.Int64()] {
+					t.Errorf("%s(%s): %d is mapped to %s, out of range or already seen", t.Name(), a.alg, x, y)
+				}
+				seen[y.Int64()] = true
+
+				var z, errDec = cryptInt(dec, big.NewInt(n), y)
+				if errDec != nil {
+					t.Fatalf("%s(%s): %s", t.Name(), a.alg, errDec)
+				}
+				if z.Int64() != x {
+					t.Errorf("%s(%s):\nhave %s\nwant %d", t.Name(), a.alg, z, x)
+				}
+			}
+		}
+	}
+}
+
+func TestCryptIntErrors(t *testing.T) {
+	var p, err = newFpeProcessor(Config{Key: commonKey128}, nil)
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+	var enc = p.modes(p.tweak, false)
+
+	if _, errSmall := cryptInt(enc, big.NewInt(99), big.NewInt(0)); errSmall != ErrDomainTooSmall {
+		t.Errorf("%s:\nhave %v\nwant %v", t.Name(), errSmall, ErrDomainTooSmall)
+	}
+	if _, errRange := cryptInt(enc, big.NewInt(100), big.NewInt(100)); errRange == nil {
+		t.Errorf("%s: cryptInt should fail on a value out of range", t.Name())
+	}
+}
+
+func TestCryptIntMaxLength(t *testing.T) {
+	// 10^56 has 56 decimal digits below it, the maximum length of FF3 and FF3-1
+	var n56 = new(big.Int).Exp(big.NewInt(10), big.NewInt(56), nil)
+	var n57 = new(big.Int).Mul(n56, big.NewInt(10))
+	for _, a := range configAlgorithms {
+		var p, err = newFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak}, nil)
+		if err != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+		var enc = p.modes(p.tweak, false)
+
+		if _, err56 := cryptInt(enc, n56, big.NewInt(42)); err56 != nil {
+			t.Errorf("%s(%s): %s", t.Name(), a.alg, err56)
+		}
+		var _, err57 = cryptInt(enc, n57, big.NewInt(42))
+		var _, isLengthError = err57.(*NumeralStringLengthError)
+		if isLengthError != (a.alg != FF1) {
+			t.Errorf("%s(%s): cryptInt of a 57-digit domain = %v", t.Name(), a.alg, err57)
+		}
+	}
+}
+
+func TestMaxNumeralStringLen(t *testing.T) {
+	for _, test := range []struct {
+		radix uint32
+		l     int
+	}{{2, 192}, {10, 56}, {16, 48}, {26, 40}, {36, 36}, {65536, 12}} {
+		for _, alg := range []Algorithm{FF3, FF31} {
+			if l := maxNumeralStringLen(alg, test.radix); l != test.l {
+				t.Errorf("%s(%s, %d):\nhave %d\nwant %d", t.Name(), alg, test.radix, l, test.l)
+			}
+		}
+	}
+	if l := maxNumeralStringLen(FF1, decimalRadix); l != -1 {
+		t.Errorf("%s(%s):\nhave %d\nwant -1", t.Name(), FF1, l)
+	}
+}
+
+func TestNumeralStringIntConversion(t *testing.T) {
+	var x = big.NewInt(4095)
+	var numeralString = intToNumeralString(x, 16, 5)
+	var expected = []uint16{0, 0, 15, 15, 15}
+	for i := range expected {
+		if numeralString[i] != expected[i] {
+			t.Fatalf("%s:\nhave %v\nwant %v", t.Name(), numeralString, expected)
+		}
+	}
+	if back := numeralStringToInt(numeralString, 16); back.Cmp(x) != 0 {
+		t.Errorf("%s:\nhave %s\nwant %s", t.Name(), back, x)
+	}
+}
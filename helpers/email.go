@@ -0,0 +1,121 @@
+// An email address is composed by a local part and a domain separated by the last '@'.
+// The local part is made of dot-separated atoms, and may carry a "+tag" sub-address.
+package helper
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// Subset of the RFC 5322 atext characters accepted by every mail system. The other
+	// characters of the local part, including '.' and '+', are left in place.
+	emailLocalAlphabet = lowerAlphabet + upperAlphabet + digitAlphabet + "-_"
+	// Letters and digits of a DNS label. Hyphens are left in place.
+	emailLabelAlphabet = lowerAlphabet + upperAlphabet + digitAlphabet
+	// Reserved by RFC 2606
+	defaultTestDomain = "example.com"
+)
+
+var (
+	emailLocalCodec = newPassthroughCodec(emailLocalAlphabet)
+	emailLabelCodec = newPassthroughCodec(emailLabelAlphabet)
+)
+
+// ErrInvalidEmail is returned when an email address has no '@', or an empty local part
+// or domain.
+var ErrInvalidEmail = errors.New("helper: invalid email address")
+
+// EmailDomainPolicy tells how the domain of an email address is processed.
+type EmailDomainPolicy int
+
+const (
+	// KeepDomain leaves the domain unchanged.
+	KeepDomain EmailDomainPolicy = iota
+	// EncryptDomainLabel enciphers the leftmost label of the domain, e.g. "example" in
+	// "example.co.uk".
+	EncryptDomainLabel
+	// TestDomain replaces the domain with EmailOptions.TestDomain. The original domain
+	// is lost, so the address deciphers with the test domain.
+	TestDomain
+)
+
+// EmailOptions configures the processors of an EmailField.
+type EmailOptions struct {
+	// Domain is the policy for the domain of the addresses.
+	Domain EmailDomainPolicy
+	// TestDomain is the domain used by the TestDomain policy, "example.com" by default.
+	TestDomain string
+}
+
+// FpeEmail enciphers the local part of email addresses, keeping its dots and plus-tags,
+// and processes their domain according to EmailOptions.Domain.
+type FpeEmail = FpeProcessor
+
+// NewFpeEmailProcessor returns an email processor. The field type of the configuration is
+// ignored.
+func NewFpeEmailProcessor(c Config) (FpeEmail, error) {
+	c.Field = EmailField
+	return NewFpeProcessor(c)
+}
+
+// emailCodec enciphers the letters, digits, '-' and '_' of the local part of email
+// addresses, and processes their domain according to the options.
+type emailCodec struct {
+	opts EmailOptions
+}
+
+func (c emailCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var at = strings.LastIndex(in, "@")
+	if at <= 0 || at == len(in)-1 {
+		return "", ErrInvalidEmail
+	}
+	var local, domain = in[:at], in[at+1:]
+
+	var localOut, err = cryptEmailPart(newMode, emailLocalCodec, local)
+	if err != nil {
+		return "", err
+	}
+
+	switch c.opts.Domain {
+	case EncryptDomainLabel:
+		var label, rest = domain, ""
+		if dot := strings.Index(domain, "."); dot >= 0 {
+			label, rest = domain[:dot], domain[dot:]
+		}
+		if label, err = cryptEmailPart(newMode, emailLabelCodec, label); err != nil {
+			return "", err
+		}
+		domain = label + rest
+	case TestDomain:
+		domain = c.opts.TestDomain
+		if domain == "" {
+			domain = defaultTestDomain
+		}
+	}
+
+	return localOut + "@" + domain, nil
+}
+
+// cryptEmailPart enciphers the characters of part that are in the alphabet of codec. It
+// returns ErrDomainTooSmall if they have less than 100 possible values.
+func cryptEmailPart(newMode modeFunc, codec *stringCodec, part string) (string, error) {
+	var n = 0
+	for _, r := range part {
+		if _, ok := codec.alphabetMap[r]; ok {
+			n++
+		}
+	}
+	if !codec.largeDomain(n) {
+		return "", ErrDomainTooSmall
+	}
+	return codec.crypt(newMode, part)
+}
+
+// newPassthroughCodec returns a string codec leaving the characters that are not in the
+// alphabet in place.
+func newPassthroughCodec(alphabet string) *stringCodec {
+	var codec, _ = newStringCodec(alphabet)
+	codec.opts.Passthrough = true
+	return codec
+}
@@ -0,0 +1,118 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+)
+
+var emailAddresses = []string{
+	"john.doe@example.com",
+	"John.Doe+newsletter@mail.example.co.uk",
+	"jd@xy.org",
+	"first_last-99@sub-domain.example",
+	"o'brien.x@example.ie",
+	"\"john doe\"@example.com",
+	"user@[192.168.0.1]",
+}
+
+func TestEmailEncryptDecrypt(t *testing.T) {
+	for _, policy := range []EmailDomainPolicy{KeepDomain, EncryptDomainLabel} {
+		var p, err = NewFpeEmailProcessor(Config{Key: commonKey128, Tweak: commonTweak, Email: EmailOptions{Domain: policy}})
+		if err != nil {
+			t.Fatalf("%s(%d): %s", t.Name(), policy, err)
+		}
+
+		for _, email := range emailAddresses {
+			var enc, errEnc = p.Encrypt(email)
+			if errEnc != nil {
+				t.Errorf("%s(%d, %s): %s", t.Name(), policy, email, errEnc)
+				continue
+			}
+
+			// The structure of the address is kept
+			var at = strings.LastIndex(email, "@")
+			if len(enc) != len(email) || enc[at] != '@' {
+				t.Errorf("%s(%d): %s does not have the structure of %s", t.Name(), policy, enc, email)
+				continue
+			}
+			for i := range email {
+				var kept = strings.IndexByte(emailLocalAlphabet, email[i]) < 0
+				if i > at {
+					kept = policy == KeepDomain || strings.IndexByte(emailLabelAlphabet, email[i]) < 0 ||
+						strings.IndexByte(email[at:i], '.') >= 0
+				}
+				if kept && enc[i] != email[i] {
+					t.Errorf("%s(%d): %s does not keep %q at index %d of %s", t.Name(), policy, enc, email[i], i, email)
+					break
+				}
+			}
+
+			var dec, errDec = p.Decrypt(enc)
+			if errDec != nil {
+				t.Errorf("%s(%d, %s): %s", t.Name(), policy, email, errDec)
+				continue
+			}
+			if strings.Compare(dec, email) != 0 {
+				t.Errorf("%s(%d):\nhave %s\nwant %s", t.Name(), policy, dec, email)
+			}
+		}
+	}
+}
+
+func TestEmailTestDomain(t *testing.T) {
+	var testDomains = []struct {
+		option string
+		domain string
+	}{
+		{"", "example.com"},
+		{"customers.test", "customers.test"},
+	}
+
+	for _, test := range testDomains {
+		var p, err = NewFpeEmailProcessor(Config{
+			Key:   commonKey128,
+			Tweak: commonTweak,
+			Email: EmailOptions{Domain: TestDomain, TestDomain: test.option},
+		})
+		if err != nil {
+			t.Fatalf("%s: %s", t.Name(), err)
+		}
+
+		var enc, errEnc = p.Encrypt("john.doe+crm@corp.example.org")
+		if errEnc != nil {
+			t.Fatalf("%s: %s", t.Name(), errEnc)
+		}
+		if !strings.HasSuffix(enc, "@"+test.domain) {
+			t.Errorf("%s: %s is not in the test domain %s", t.Name(), enc, test.domain)
+		}
+		var dec, _ = p.Decrypt(enc)
+		if strings.Compare(dec, "john.doe+crm@"+test.domain) != 0 {
+			t.Errorf("%s:\nhave %s\nwant %s", t.Name(), dec, "john.doe+crm@"+test.domain)
+		}
+	}
+}
+
+func TestEmailErrors(t *testing.T) {
+	var p, err = NewFpeEmailProcessor(Config{Key: commonKey128, Email: EmailOptions{Domain: EncryptDomainLabel}})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	var invalidEmails = []struct {
+		email string
+		err   error
+	}{
+		{"", ErrInvalidEmail},
+		{"john.doe", ErrInvalidEmail},
+		{"@example.com", ErrInvalidEmail},
+		{"john.doe@", ErrInvalidEmail},
+		{"j@example.com", ErrDomainTooSmall},
+		{"j.d+x@example.com", nil},
+		{"john@x.com", ErrDomainTooSmall},
+	}
+	for _, test := range invalidEmails {
+		if _, errEnc := p.Encrypt(test.email); errEnc != test.err {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), test.email, errEnc, test.err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// An IBAN (ISO 13616) is composed by a two-letter country code, two check digits and a
+// country-specific Basic Bank Account Number (BBAN) of up to 30 characters.
+package helper
+
+import (
+	"errors"
+	"fmt"
+)
+
+const alnumAlphabet = digitAlphabet + upperAlphabet
+
+var alnumCodec, _ = newStringCodec(alnumAlphabet)
+
+var (
+	// ErrIBANFormat is returned when an IBAN contains lowercase letters or characters
+	// other than spaces, or does not start with a country code and two check digits.
+	ErrIBANFormat = errors.New("helper: invalid IBAN format")
+	// ErrIBANCountry is returned when the country of an IBAN is unknown.
+	ErrIBANCountry = errors.New("helper: unknown IBAN country code")
+	// ErrIBANLength is returned when an IBAN does not have the length of its country.
+	ErrIBANLength = errors.New("helper: invalid IBAN length")
+	// ErrIBANCheckDigits is returned when the check digits of an IBAN are invalid.
+	ErrIBANCheckDigits = errors.New("helper: invalid IBAN check digits")
+)
+
+// FpeIBAN enciphers IBANs, keeping the country code and the structure of the BBAN, and
+// recomputes the check digits so that the output is a valid IBAN.
+type FpeIBAN = FpeProcessor
+
+// NewFpeIBANProcessor returns an IBAN processor. The field type of the configuration is
+// ignored.
+func NewFpeIBANProcessor(c Config) (FpeIBAN, error) {
+	c.Field = IBANField
+	return NewFpeProcessor(c)
+}
+
+// ibanBBANFormats gives the structure of the BBAN of each country, as in the SWIFT IBAN
+// registry: n for digits, a for uppercase letters and c for both.
+var ibanBBANFormats = map[string]string{
+	"AD": "8n12c", "AE": "19n", "AL": "8n16c", "AT": "16n", "AZ": "4a20c",
+	"BA": "16n", "BE": "12n", "BG": "4a6n8c", "BH": "4a14c", "BR": "23n1a1c",
+	"BY": "4c4n16c", "CH": "5n12c", "CR": "18n", "CY": "8n16c", "CZ": "20n",
+	"DE": "18n", "DK": "14n", "DO": "4c20n", "EE": "16n", "EG": "25n",
+	"ES": "20n", "FI": "14n", "FO": "14n", "FR": "10n11c2n", "GB": "4a14n",
+	"GE": "2a16n", "GI": "4a15c", "GL": "14n", "GR": "7n16c", "GT": "24c",
+	"HR": "17n", "HU": "24n", "IE": "4a14n", "IL": "19n", "IQ": "4a15n",
+	"IS": "22n", "IT": "1a10n12c", "JO": "4a4n18c", "KW": "4a22c", "KZ": "3n13c",
+	"LB": "4n20c", "LC": "4a24c", "LI": "5n12c", "LT": "16n", "LU": "3n13c",
+	"LV": "4a13c", "MC": "10n11c2n", "MD": "20c", "ME": "18n", "MK": "3n10c2n",
+	"MR": "23n", "MT": "4a5n18c", "MU": "4a19n3a", "NL": "4a10n", "NO": "11n",
+	"PK": "4a16c", "PL": "24n", "PS": "4a21c", "PT": "21n", "QA": "4a21c",
+	"RO": "4a16c", "RS": "18n", "SA": "2n18c", "SC": "4a20n3a", "SE": "20n",
+	"SI": "15n", "SK": "20n", "SM": "1a10n12c", "TL": "19n", "TN": "20n",
+	"TR": "6n16c", "UA": "6n19c", "VA": "18n", "VG": "4a16n", "XK": "16n",
+}
+
+// ibanCodec enciphers the BBAN of an IBAN according to the structure of its country, and
+// recomputes the check digits. The country code and the spaces are left in place.
+// National check digits inside the BBAN are not recomputed.
+type ibanCodec struct{}
+
+func (c ibanCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var runes = []rune(in)
+	var positions = make([]int, 0, len(runes))
+	var compact = make([]rune, 0, len(runes))
+
+	for i, r := range runes {
+		switch {
+		case r == ' ':
+		case (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z'):
+			positions = append(positions, i)
+			compact = append(compact, r)
+		default:
+			return "", ErrIBANFormat
+		}
+	}
+
+	if len(compact) < 4 || charClassOf(compact[0]) != upperCodec || charClassOf(compact[1]) != upperCodec ||
+		charClassOf(compact[2]) != digitCodec || charClassOf(compact[3]) != digitCodec {
+		return "", ErrIBANFormat
+	}
+	var format, ok = ibanBBANFormats[string(compact[:2])]
+	if !ok {
+		return "", ErrIBANCountry
+	}
+	var alphabets, err = parseBBANFormat(format)
+	if err != nil {
+		return "", err
+	}
+	if len(compact) != 4+len(alphabets) {
+		return "", ErrIBANLength
+	}
+	if !Mod97Radix10.Validate(string(compact[4:]) + string(compact[:4])) {
+		return "", ErrIBANCheckDigits
+	}
+
+	if err = cryptMixedRadix(newMode, runes, positions[4:], alphabets); err != nil {
+		return "", err
+	}
+
+	// Compute ciphertext check digits
+	for k, i := range positions {
+		compact[k] = runes[i]
+	}
+	var check, errCheck = Mod97Radix10.Compute(string(compact[4:]) + string(compact[:2]))
+	if errCheck != nil {
+		return "", errCheck
+	}
+	runes[positions[2]] = rune(check[0])
+	runes[positions[3]] = rune(check[1])
+
+	return string(runes), nil
+}
+
+// parseBBANFormat returns the alphabet of each position of a BBAN format like "4a6n8c".
+func parseBBANFormat(format string) ([]*stringCodec, error) {
+	var alphabets []*stringCodec
+	var count = 0
+	for _, r := range format {
+		if r >= '0' && r <= '9' {
+			count = count*10 + int(r-'0')
+			continue
+		}
+
+		var codec *stringCodec
+		switch r {
+		case 'n':
+			codec = digitCodec
+		case 'a':
+			codec = upperCodec
+		case 'c':
+			codec = alnumCodec
+		default:
+			return nil, fmt.Errorf("parseBBANFormat: invalid character class %q in %s", r, format)
+		}
+		for ; count > 0; count-- {
+			alphabets = append(alphabets, codec)
+		}
+	}
+	return alphabets, nil
+}
@@ -0,0 +1,119 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+)
+
+var validIBANs = []string{
+	"DE89370400440532013000",
+	"DE89 3704 0044 0532 0130 00",
+	"GB82WEST12345698765432",
+	"GB82 WEST 1234 5698 7654 32",
+	"FR1420041010050500013M02606",
+	"NL91ABNA0417164300",
+	"BE68539007547034",
+	"CH9300762011623852957",
+	"ES9121000418450200051332",
+	"IT60X0542811101000000123456",
+	"AT611904300234573201",
+	"PL61109010140000071219812874",
+	"NO9386011117947",
+	"MT84MALT011000012345MTLCAST001S",
+	"SE4550000000058398257466",
+	"PT50000201231234567890154",
+	"LC55HEMM000100010012001200023015",
+}
+
+func TestIBANEncryptDecrypt(t *testing.T) {
+	for _, a := range configAlgorithms {
+		var p, err = NewFpeIBANProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak})
+		if err != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+
+		for _, iban := range validIBANs {
+			var enc, errEnc = p.Encrypt(iban)
+			if errEnc != nil {
+				t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, iban, errEnc)
+				continue
+			}
+			if strings.Compare(enc, iban) == 0 {
+				t.Errorf("%s(%s): ciphertext equals plaintext %s", t.Name(), a.alg, enc)
+			}
+			checkIBANShape(t, iban, enc)
+
+			var dec, errDec = p.Decrypt(enc)
+			if errDec != nil {
+				t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, iban, errDec)
+				continue
+			}
+			if strings.Compare(dec, iban) != 0 {
+				t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, iban)
+			}
+		}
+	}
+}
+
+// checkIBANShape checks that enc is a valid IBAN with the country code, spaces and BBAN
+// structure of iban.
+func checkIBANShape(t *testing.T, iban, enc string) {
+	if len(enc) != len(iban) || enc[:2] != iban[:2] {
+		t.Errorf("%s: %s does not have the country and length of %s", t.Name(), enc, iban)
+		return
+	}
+	var compact = strings.Replace(enc, " ", "", -1)
+	if !Mod97Radix10.Validate(compact[4:] + compact[:4]) {
+		t.Errorf("%s: %s has invalid check digits", t.Name(), enc)
+	}
+	var alphabets, _ = parseBBANFormat(ibanBBANFormats[iban[:2]])
+	for k, r := range compact[4:] {
+		if _, ok := alphabets[k].alphabetMap[r]; !ok {
+			t.Errorf("%s: %s does not follow the BBAN format %s", t.Name(), enc, ibanBBANFormats[iban[:2]])
+			return
+		}
+	}
+	for i := range iban {
+		if (iban[i] == ' ') != (enc[i] == ' ') {
+			t.Errorf("%s: %s does not keep the spaces of %s", t.Name(), enc, iban)
+			return
+		}
+	}
+}
+
+func TestIBANErrors(t *testing.T) {
+	var p, err = NewFpeIBANProcessor(Config{Key: commonKey128})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	var invalidIBANs = []struct {
+		iban string
+		err  error
+	}{
+		{"", ErrIBANFormat},
+		{"de89370400440532013000", ErrIBANFormat},
+		{"DE89-3704-0044-0532-0130-00", ErrIBANFormat},
+		{"D989370400440532013000", ErrIBANFormat},
+		{"ZZ89370400440532013000", ErrIBANCountry},
+		{"DE8937040044053201300", ErrIBANLength},
+		{"DE88370400440532013000", ErrIBANCheckDigits},
+	}
+	for _, test := range invalidIBANs {
+		if _, errEnc := p.Encrypt(test.iban); errEnc != test.err {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), test.iban, errEnc, test.err)
+		}
+	}
+}
+
+func TestIBANBBANFormats(t *testing.T) {
+	for country, format := range ibanBBANFormats {
+		var alphabets, err = parseBBANFormat(format)
+		if err != nil {
+			t.Errorf("%s(%s): %s", t.Name(), country, err)
+		}
+		if len(alphabets) < 11 || len(alphabets) > 30 {
+			t.Errorf("%s(%s): invalid BBAN length %d", t.Name(), country, len(alphabets))
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// An IMEI is composed by an 8-digit Type Allocation Code (TAC) identifying the device
+// model, a 6-digit serial number and a Luhn check digit. An IMEISV has a 2-digit software
+// version number (SVN) instead of the check digit.
+package helper
+
+import (
+	"errors"
+)
+
+const (
+	tacLen    = 8
+	serialLen = 6
+	imeiLen   = 15
+	imeisvLen = 16
+)
+
+// ErrInvalidIMEI is returned when an IMEI does not have 15 digits or an IMEISV 16 digits,
+// or when they contain other separators than spaces, '-' and '/'.
+var ErrInvalidIMEI = errors.New("helper: invalid IMEI")
+
+// imeiCodec enciphers the serial number of IMEIs and IMEISVs, leaving the TAC, the SVN and
+// the separators in place. The check digit of IMEIs is recomputed, and IMEIs with an
+// invalid check digit are rejected with ErrInvalidCheckDigit.
+type imeiCodec struct{}
+
+func (c imeiCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var runes = []rune(in)
+	var numeralString = make([]uint16, 0, imeisvLen)
+
+	// Create numeral string
+	for _, r := range runes {
+		switch {
+		case r >= '0' && r <= '9':
+			if len(numeralString) == imeisvLen {
+				return "", ErrInvalidIMEI
+			}
+			numeralString = append(numeralString, uint16(r-'0'))
+		case r != ' ' && r != '-' && r != '/':
+			return "", ErrInvalidIMEI
+		}
+	}
+
+	switch len(numeralString) {
+	case imeiLen:
+		if err := cryptCheckDigit(newMode, Luhn, runes, tacLen, nil); err != nil {
+			return "", err
+		}
+		return string(runes), nil
+	case imeisvLen:
+	default:
+		return "", ErrInvalidIMEI
+	}
+
+	// Encrypt serial number
+	if err := cryptDigits(newMode, runes, tacLen, tacLen+serialLen, nil); err != nil {
+		return "", err
+	}
+
+	return string(runes), nil
+}
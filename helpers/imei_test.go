@@ -0,0 +1,75 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIMEIEncryptDecrypt(t *testing.T) {
+	var imeis = []string{
+		"490154203237518",
+		"35-209900-176148-1",
+		"35 209900 176148 1",
+		"3520990017614823",
+		"35-209900-176148-23",
+	}
+
+	for _, a := range configAlgorithms {
+		var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: IMEIField})
+		if err != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+
+		for _, imei := range imeis {
+			var enc, errEnc = p.Encrypt(imei)
+			if errEnc != nil {
+				t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, imei, errEnc)
+				continue
+			}
+
+			// TAC, SVN and separators are kept, and IMEIs are valid
+			var compact, encCompact = strings.NewReplacer(" ", "", "-", "").Replace(imei), strings.NewReplacer(" ", "", "-", "").Replace(enc)
+			if len(enc) != len(imei) || len(encCompact) != len(compact) || encCompact[:tacLen] != compact[:tacLen] {
+				t.Errorf("%s(%s): %s does not keep the TAC and format of %s", t.Name(), a.alg, enc, imei)
+			}
+			if len(compact) == imeisvLen && encCompact[tacLen+serialLen:] != compact[tacLen+serialLen:] {
+				t.Errorf("%s(%s): %s does not keep the SVN of %s", t.Name(), a.alg, enc, imei)
+			}
+			if len(compact) == imeiLen && !Luhn.Validate(encCompact) {
+				t.Errorf("%s(%s): %s has an invalid check digit", t.Name(), a.alg, enc)
+			}
+
+			var dec, errDec = p.Decrypt(enc)
+			if errDec != nil {
+				t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, imei, errDec)
+				continue
+			}
+			if strings.Compare(dec, imei) != 0 {
+				t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, imei)
+			}
+		}
+	}
+}
+
+func TestIMEIErrors(t *testing.T) {
+	var p, err = NewFpeProcessor(Config{Key: commonKey128, Field: IMEIField})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	var errorTests = []struct {
+		imei string
+		err  error
+	}{
+		{"", ErrInvalidIMEI},
+		{"49015420323751", ErrInvalidIMEI},
+		{"49015420323751812", ErrInvalidIMEI},
+		{"IMEI 490154203237518", ErrInvalidIMEI},
+		{"490154203237519", ErrInvalidCheckDigit},
+	}
+	for _, test := range errorTests {
+		if _, errEnc := p.Encrypt(test.imei); errEnc != test.err {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), test.imei, errEnc, test.err)
+		}
+	}
+}
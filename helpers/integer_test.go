@@ -0,0 +1,146 @@
+package helper
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestInt64EncryptDecrypt(t *testing.T) {
+	var ranges = []struct {
+		lo, hi int64
+	}{
+		{0, 99},
+		{1000, 1099},
+		{-500, 500},
+		{100000, 2999999},
+		{math.MinInt64, math.MaxInt64},
+	}
+
+	for _, a := range configAlgorithms {
+		for _, r := range ranges {
+			var p, err = NewFpeInt64Processor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak}, r.lo, r.hi)
+			if err != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+			}
+
+			for _, x := range []int64{r.lo, r.lo + 1, r.lo/2 + r.hi/2, r.hi - 1, r.hi} {
+				var enc, errEnc = p.Encrypt(x)
+				if errEnc != nil {
+					t.Errorf("%s(%s, %d): %s", t.Name(), a.alg, x, errEnc)
+					continue
+				}
+				if enc < r.lo || enc > r.hi {
+					t.Errorf("%s(%s): %d not in range [%d, %d]", t.Name(), a.alg, enc, r.lo, r.hi)
+				}
+				var dec, errDec = p.Decrypt(enc)
+				if errDec != nil {
+					t.Errorf("%s(%s, %d): %s", t.Name(), a.alg, x, errDec)
+					continue
+				}
+				if dec != x {
+					t.Errorf("%s(%s):\nhave %d\nwant %d", t.Name(), a.alg, dec, x)
+				}
+			}
+		}
+	}
+}
+
+func TestUint64EncryptDecrypt(t *testing.T) {
+	var ranges = []struct {
+		lo, hi uint64
+	}{
+		{0, 99},
+		{10000000, 99999999},
+		{0, math.MaxUint64},
+	}
+
+	for _, a := range configAlgorithms {
+		for _, r := range ranges {
+			var p, err = NewFpeUint64Processor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak}, r.lo, r.hi)
+			if err != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+			}
+
+			for _, x := range []uint64{r.lo, r.lo + 1, r.lo/2 + r.hi/2, r.hi - 1, r.hi} {
+				var enc, errEnc = p.Encrypt(x)
+				if errEnc != nil {
+					t.Errorf("%s(%s, %d): %s", t.Name(), a.alg, x, errEnc)
+					continue
+				}
+				if enc < r.lo || enc > r.hi {
+					t.Errorf("%s(%s): %d not in range [%d, %d]", t.Name(), a.alg, enc, r.lo, r.hi)
+				}
+				var dec, errDec = p.Decrypt(enc)
+				if errDec != nil {
+					t.Errorf("%s(%s, %d): %s", t.Name(), a.alg, x, errDec)
+					continue
+				}
+				if dec != x {
+					t.Errorf("%s(%s):\nhave %d\nwant %d", t.Name(), a.alg, dec, x)
+				}
+			}
+		}
+	}
+}
+
+func TestIntRangePermutation(t *testing.T) {
+	var p, err = NewFpeInt64Processor(Config{Key: commonKey128, Tweak: commonTweak}, -100, 149)
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	var seen = map[int64]bool{}
+	for x := int64(-100); x <= 149; x++ {
+		var enc, errEnc = p.Encrypt(x)
+		if errEnc != nil {
+			t.Fatalf("%s(%d): %s", t.Name(), x, errEnc)
+		}
+		if seen[enc] {
+			t.Errorf("%s: %d enciphered twice", t.Name(), enc)
+		}
+		seen[enc] = true
+	}
+}
+
+func TestIntRangeWithTweak(t *testing.T) {
+	var p, err = NewFpeUint64Processor(Config{Key: commonKey128, Algorithm: FF3, Tweak: ff3CommonTweak1}, 1, 1000000)
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	var enc, errEnc = p.EncryptWithTweak(context.Background(), 424242, ff3CommonTweak2)
+	if errEnc != nil {
+		t.Fatalf("%s: %s", t.Name(), errEnc)
+	}
+	var dec, errDec = p.DecryptWithTweak(context.Background(), enc, ff3CommonTweak2)
+	if errDec != nil {
+		t.Fatalf("%s: %s", t.Name(), errDec)
+	}
+	if dec != 424242 {
+		t.Errorf("%s:\nhave %d\nwant %d", t.Name(), dec, 424242)
+	}
+	if _, err = p.EncryptWithTweak(context.Background(), 424242, ff3CommonTweak2[:7]); err == nil {
+		t.Errorf("%s: invalid tweak length should be rejected", t.Name())
+	}
+}
+
+func TestIntRangeErrors(t *testing.T) {
+	var c = Config{Key: commonKey128}
+	if _, err := NewFpeInt64Processor(c, 0, 98); err != ErrDomainTooSmall {
+		t.Errorf("%s:\nhave %v\nwant %v", t.Name(), err, ErrDomainTooSmall)
+	}
+	if _, err := NewFpeUint64Processor(c, 10, 9); err == nil {
+		t.Errorf("%s: empty range should be rejected", t.Name())
+	}
+
+	var p, err = NewFpeInt64Processor(c, 0, 999)
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+	for _, x := range []int64{-1, 1000, math.MaxInt64} {
+		if _, errEnc := p.Encrypt(x); errEnc != ErrIntOutOfRange {
+			t.Errorf("%s(%d):\nhave %v\nwant %v", t.Name(), x, errEnc, ErrIntOutOfRange)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// An IP address is composed by a network prefix and host bits. IPv4 addresses are written
+// in dotted decimal notation and IPv6 addresses as hexadecimal groups, as in RFC 4291.
+package helper
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+)
+
+// The host part of an address must have at least 100 values
+const minIPHostBits = 7
+
+// IPFormat selects how enciphered IPv6 addresses are written.
+type IPFormat int
+
+const (
+	// CanonicalIPFormat writes addresses in the RFC 5952 canonical form.
+	CanonicalIPFormat IPFormat = iota
+	// OriginalIPFormat writes IPv6 addresses with the hexadecimal case of the input, and
+	// without "::" compression if the input has none, with 4-digit groups if the input
+	// has 4-digit groups.
+	OriginalIPFormat
+)
+
+// IPOptions configures the processors of an IPField.
+type IPOptions struct {
+	// IPv4PrefixLen is the number of leading bits of IPv4 addresses left in clear, at
+	// most 25. IPv4-mapped IPv6 addresses are handled as IPv4 addresses.
+	IPv4PrefixLen int
+	// IPv6PrefixLen is the number of leading bits of IPv6 addresses left in clear, at
+	// most 121.
+	IPv6PrefixLen int
+	// Format is the textual form of the enciphered addresses.
+	Format IPFormat
+}
+
+// ipCodec enciphers the host bits of IP addresses into an address of the same family and
+// network. The zone of IPv6 addresses is left unchanged.
+type ipCodec struct {
+	opts IPOptions
+}
+
+func newIPCodec(opts IPOptions) (*ipCodec, error) {
+	if opts.IPv4PrefixLen < 0 || opts.IPv6PrefixLen < 0 {
+		return nil, fmt.Errorf("newIPCodec: negative prefix length")
+	}
+	if opts.IPv4PrefixLen > 32-minIPHostBits || opts.IPv6PrefixLen > 128-minIPHostBits {
+		return nil, ErrDomainTooSmall
+	}
+	return &ipCodec{opts}, nil
+}
+
+func (c *ipCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var addr, err = netip.ParseAddr(in)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case addr.Is4():
+		var bytes = addr.As4()
+		if err = cryptHostBits(newMode, bytes[:], c.opts.IPv4PrefixLen); err != nil {
+			return "", err
+		}
+		return netip.AddrFrom4(bytes).String(), nil
+	case addr.Is4In6():
+		var bytes = addr.Unmap().As4()
+		if err = cryptHostBits(newMode, bytes[:], c.opts.IPv4PrefixLen); err != nil {
+			return "", err
+		}
+		return netip.AddrFrom16(netip.AddrFrom4(bytes).As16()).WithZone(addr.Zone()).String(), nil
+	default:
+		var bytes = addr.As16()
+		if err = cryptHostBits(newMode, bytes[:], c.opts.IPv6PrefixLen); err != nil {
+			return "", err
+		}
+		var out = netip.AddrFrom16(bytes)
+		if c.opts.Format == OriginalIPFormat {
+			return formatIPv6Like(out, in) + zoneSuffix(addr.Zone()), nil
+		}
+		return out.WithZone(addr.Zone()).String(), nil
+	}
+}
+
+// cryptHostBits enciphers the bits of the big-endian address following the prefix, as an
+// integer with cycle-walking.
+func cryptHostBits(newMode modeFunc, bytes []byte, prefixLen int) error {
+	var hostBits = uint(len(bytes)*8 - prefixLen)
+	var n = new(big.Int).Lsh(big.NewInt(1), hostBits)
+
+	var address = new(big.Int).SetBytes(bytes)
+	var host = new(big.Int).And(address, new(big.Int).Sub(n, big.NewInt(1)))
+	var y, err = cryptInt(newMode, n, host)
+	if err != nil {
+		return err
+	}
+
+	address.Sub(address, host).Add(address, y)
+	address.FillBytes(bytes)
+	return nil
+}
+
+// formatIPv6Like writes addr in the textual form of the IPv6 address in, without zone.
+func formatIPv6Like(addr netip.Addr, in string) string {
+	var zone = strings.IndexByte(in, '%')
+	if zone >= 0 {
+		in = in[:zone]
+	}
+
+	var out string
+	switch {
+	case strings.Contains(in, "::") || strings.Contains(in, "."):
+		out = addr.String()
+	case len(in) == len(addr.StringExpanded()):
+		out = addr.StringExpanded()
+	default:
+		var bytes = addr.As16()
+		var groups = make([]string, 8)
+		for i := range groups {
+			groups[i] = fmt.Sprintf("%x", uint16(bytes[2*i])<<8|uint16(bytes[2*i+1]))
+		}
+		out = strings.Join(groups, ":")
+	}
+
+	if strings.ContainsAny(in, "ABCDEF") {
+		out = strings.ToUpper(out)
+	}
+	return out
+}
+
+// zoneSuffix returns the zone of an IPv6 address as written after the address.
+func zoneSuffix(zone string) string {
+	if zone == "" {
+		return ""
+	}
+	return "%" + zone
+}
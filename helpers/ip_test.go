@@ -0,0 +1,129 @@
+package helper
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+var ipAddresses = []string{
+	"192.168.1.42",
+	"10.0.0.1",
+	"255.255.255.255",
+	"0.0.0.0",
+	"2001:db8::1",
+	"2001:db8:85a3::8a2e:370:7334",
+	"fe80::1%eth0",
+	"::ffff:192.0.2.128",
+	"::",
+}
+
+func TestIPEncryptDecrypt(t *testing.T) {
+	var prefixes = []IPOptions{
+		{},
+		{IPv4PrefixLen: 24, IPv6PrefixLen: 48},
+		{IPv4PrefixLen: 16, IPv6PrefixLen: 64},
+		{IPv4PrefixLen: 25, IPv6PrefixLen: 121},
+	}
+
+	for _, a := range configAlgorithms {
+		for _, opts := range prefixes {
+			var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: IPField, IP: opts})
+			if err != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+			}
+
+			for _, ip := range ipAddresses {
+				var enc, errEnc = p.Encrypt(ip)
+				if errEnc != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, ip, errEnc)
+					continue
+				}
+
+				// Same family, zone and network
+				var in, out = netip.MustParseAddr(ip), netip.MustParseAddr(enc)
+				var prefixLen = opts.IPv6PrefixLen
+				if in.Unmap().Is4() {
+					in, out, prefixLen = in.Unmap(), out.Unmap(), opts.IPv4PrefixLen
+				}
+				if in.BitLen() != out.BitLen() || in.Zone() != out.Zone() {
+					t.Errorf("%s(%s): %s does not have the family of %s", t.Name(), a.alg, enc, ip)
+				}
+				var network, _ = in.WithZone("").Prefix(prefixLen)
+				if !network.Contains(out.WithZone("")) {
+					t.Errorf("%s(%s): %s not in network %s", t.Name(), a.alg, enc, network)
+				}
+
+				var dec, errDec = p.Decrypt(enc)
+				if errDec != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, ip, errDec)
+					continue
+				}
+				if strings.Compare(dec, ip) != 0 {
+					t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, ip)
+				}
+			}
+		}
+	}
+}
+
+func TestIPFormat(t *testing.T) {
+	var formatTests = []struct {
+		format IPFormat
+		ip     string
+		want   func(string) bool
+	}{
+		{CanonicalIPFormat, "2001:0DB8:0000:0000:0000:0000:0000:0001", func(s string) bool {
+			return s == strings.ToLower(s) && len(s) < 39
+		}},
+		{OriginalIPFormat, "2001:0DB8:0000:0000:0000:0000:0000:0001", func(s string) bool {
+			return s == strings.ToUpper(s) && len(s) == 39
+		}},
+		{OriginalIPFormat, "2001:db8:0:0:0:0:0:1", func(s string) bool {
+			return strings.Count(s, ":") == 7 && !strings.Contains(s, "::")
+		}},
+		{OriginalIPFormat, "2001:DB8::1", func(s string) bool {
+			return s == strings.ToUpper(s)
+		}},
+	}
+
+	for _, test := range formatTests {
+		var p, err = NewFpeProcessor(Config{Key: commonKey128, Field: IPField, IP: IPOptions{IPv6PrefixLen: 32, Format: test.format}})
+		if err != nil {
+			t.Fatalf("%s: %s", t.Name(), err)
+		}
+
+		var enc, errEnc = p.Encrypt(test.ip)
+		if errEnc != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), test.ip, errEnc)
+		}
+		if !test.want(enc) {
+			t.Errorf("%s(%d): %s does not have the expected form for %s", t.Name(), test.format, enc, test.ip)
+		}
+		var dec, _ = p.Decrypt(enc)
+		if netip.MustParseAddr(dec) != netip.MustParseAddr(test.ip) {
+			t.Errorf("%s(%d):\nhave %s\nwant %s", t.Name(), test.format, dec, test.ip)
+		}
+	}
+}
+
+func TestIPErrors(t *testing.T) {
+	for _, opts := range []IPOptions{{IPv4PrefixLen: 26}, {IPv6PrefixLen: 122}} {
+		if _, err := NewFpeProcessor(Config{Key: commonKey128, Field: IPField, IP: opts}); err != ErrDomainTooSmall {
+			t.Errorf("%s(%v):\nhave %v\nwant %v", t.Name(), opts, err, ErrDomainTooSmall)
+		}
+	}
+	if _, err := NewFpeProcessor(Config{Key: commonKey128, Field: IPField, IP: IPOptions{IPv4PrefixLen: -1}}); err == nil {
+		t.Errorf("%s: negative prefix length should be rejected", t.Name())
+	}
+
+	var p, err = NewFpeProcessor(Config{Key: commonKey128, Field: IPField})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+	for _, ip := range []string{"", "256.1.1.1", "1.2.3", "192.168.001.001", "2001:db8:::1", "example.com"} {
+		if _, errEnc := p.Encrypt(ip); errEnc == nil {
+			t.Errorf("%s: %s should be rejected", t.Name(), ip)
+		}
+	}
+}
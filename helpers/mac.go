@@ -0,0 +1,163 @@
+// A MAC address (EUI-48) or EUI-64 is composed by 12 or 16 hexadecimal digits, usually
+// grouped by two with ':' or '-' separators, or by four with '.' separators. The first 6
+// digits are the Organizationally Unique Identifier (OUI) of the vendor, and the two
+// lowest bits of the first octet are the multicast (I/G) and locally administered (U/L)
+// bits.
+package helper
+
+import (
+	"crypto-fpe/fpe"
+	"errors"
+	"strings"
+)
+
+const (
+	hexAlphabet    = digitAlphabet + "abcdef"
+	hexRadix       = 16
+	ouiLen         = 6
+	eui48Len       = 12
+	eui64Len       = 16
+	macAddressBits = 0x3
+)
+
+var (
+	hexLowerCodec, _ = newStringCodec(hexAlphabet)
+	hexUpperCodec, _ = newStringCodec(strings.ToUpper(hexAlphabet))
+)
+
+// ErrInvalidMAC is returned when a MAC address does not have 12 or 16 hexadecimal digits,
+// mixes uppercase and lowercase letters, or contains other separators than ':', '-' and
+// '.'.
+var ErrInvalidMAC = errors.New("helper: invalid MAC address")
+
+// MACPolicy tells which part of a MAC address is left in clear.
+type MACPolicy int
+
+const (
+	// KeepOUI leaves the OUI in clear, and enciphers the NIC-specific part.
+	KeepOUI MACPolicy = iota
+	// KeepAddressBits leaves only the multicast and locally administered bits in clear.
+	KeepAddressBits
+)
+
+// MACOptions configures the processors of a MACField.
+type MACOptions struct {
+	// Keep is the part of the addresses left in clear.
+	Keep MACPolicy
+}
+
+// macCodec enciphers the hexadecimal digits of MAC addresses, leaving the separators in
+// place. Letters are written in uppercase if the input has uppercase letters, in
+// lowercase otherwise. Addresses mixing uppercase and lowercase letters are rejected with
+// ErrInvalidMAC, since their case could not be restored.
+//
+// An uppercase address without letters in its kept part is enciphered again until its
+// enciphered part has a letter, otherwise it would be deciphered in lowercase. Since the
+// encryption is a permutation of the addresses, this is a permutation of the addresses
+// with a letter (cycle-walking).
+type macCodec struct {
+	opts MACOptions
+}
+
+func (c macCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var runes = []rune(in)
+	var positions = make([]int, 0, eui64Len)
+	var numeralString = make([]uint16, 0, eui64Len)
+	var upper, lower = false, false
+
+	// Create numeral string
+	for i, r := range runes {
+		switch {
+		case r >= '0' && r <= '9':
+			numeralString = append(numeralString, uint16(r-'0'))
+		case r >= 'a' && r <= 'f':
+			numeralString = append(numeralString, uint16(r-'a'+10))
+			lower = true
+		case r >= 'A' && r <= 'F':
+			numeralString = append(numeralString, uint16(r-'A'+10))
+			upper = true
+		case r == ':' || r == '-' || r == '.':
+			continue
+		default:
+			return "", ErrInvalidMAC
+		}
+		positions = append(positions, i)
+	}
+	if len(numeralString) != eui48Len && len(numeralString) != eui64Len || upper && lower {
+		return "", ErrInvalidMAC
+	}
+
+	var hexCodec = hexLowerCodec
+	if upper {
+		hexCodec = hexUpperCodec
+	}
+
+	var crypt, first = cryptMACNIC, ouiLen
+	if c.opts.Keep == KeepAddressBits {
+		crypt, first = cryptMACAddressBits, 0
+	}
+	var walk = upper && !hasHexLetter(runes, positions[:first])
+	for {
+		if err := crypt(newMode, runes, positions, hexCodec); err != nil {
+			return "", err
+		}
+		if !walk || hasHexLetter(runes, positions[first:]) {
+			return string(runes), nil
+		}
+	}
+}
+
+// cryptMACNIC enciphers the NIC-specific digits of a MAC address, after the OUI.
+func cryptMACNIC(newMode modeFunc, runes []rune, positions []int, hexCodec *stringCodec) error {
+	// Create numeral string
+	var numeralString = make([]uint16, len(positions)-ouiLen)
+	for numStrIdx, i := range positions[ouiLen:] {
+		numeralString[numStrIdx] = hexCodec.alphabetMap[runes[i]]
+	}
+
+	var m, err = newMode(hexRadix, len(numeralString))
+	if err != nil {
+		return err
+	}
+
+	// Encrypt NIC-specific digits
+	var b = fpe.NumeralStringToBytes(numeralString)
+	m.CryptBlocks(b, b)
+	numeralString = fpe.BytesToNumeralString(b)
+
+	// Copy enciphered data back to runes
+	for numStrIdx, i := range positions[ouiLen:] {
+		runes[i] = hexCodec.alphabetSlice[numeralString[numStrIdx]]
+	}
+	return nil
+}
+
+// hasHexLetter tells if one of the hexadecimal digits of runes at the given positions is
+// a letter.
+func hasHexLetter(runes []rune, positions []int) bool {
+	for _, i := range positions {
+		if runes[i] > '9' {
+			return true
+		}
+	}
+	return false
+}
+
+// cryptMACAddressBits enciphers all the bits of a MAC address except the multicast and
+// locally administered bits, which are the lowest bits of the second digit.
+func cryptMACAddressBits(newMode modeFunc, runes []rune, positions []int, hexCodec *stringCodec) error {
+	var alphabets = make([]*stringCodec, len(positions))
+	for k := range alphabets {
+		alphabets[k] = hexCodec
+	}
+
+	// The second digit takes one of the 4 values with the same address bits
+	var bits = hexCodec.alphabetMap[runes[positions[1]]] & macAddressBits
+	var alphabet = make([]rune, 0, 4)
+	for high := uint16(0); high < 4; high++ {
+		alphabet = append(alphabet, hexCodec.alphabetSlice[high<<2|bits])
+	}
+	alphabets[1], _ = newStringCodec(string(alphabet))
+
+	return cryptMixedRadix(newMode, runes, positions, alphabets)
+}
@@ -0,0 +1,121 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var macAddresses = []string{
+	"00:1A:2B:3C:4D:5E",
+	"00-1a-2b-3c-4d-5e",
+	"001a.2b3c.4d5e",
+	"001A2B3C4D5E",
+	"02:00:5e:10:00:00",
+	"01:00:5E:00:00:FB",
+	"00:11:22:ff:fe:33:44:55",
+	"00:00:00:00:00:00",
+}
+
+// macBits returns the multicast and locally administered bits of a MAC address.
+func macBits(mac string) uint16 {
+	var digits = strings.NewReplacer(":", "", "-", "", ".", "").Replace(strings.ToLower(mac))
+	return uint16(strings.IndexByte(hexAlphabet, digits[1])) & macAddressBits
+}
+
+func TestMACEncryptDecrypt(t *testing.T) {
+	for _, a := range configAlgorithms {
+		for _, keep := range []MACPolicy{KeepOUI, KeepAddressBits} {
+			var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: MACField, MAC: MACOptions{keep}})
+			if err != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+			}
+
+			for _, mac := range macAddresses {
+				var enc, errEnc = p.Encrypt(mac)
+				if errEnc != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, mac, errEnc)
+					continue
+				}
+
+				// Separators, case and kept part
+				if len(enc) != len(mac) {
+					t.Errorf("%s(%s): %s does not have the format of %s", t.Name(), a.alg, enc, mac)
+					continue
+				}
+				for i := range mac {
+					if strings.IndexByte(":-.", mac[i]) >= 0 && enc[i] != mac[i] {
+						t.Errorf("%s(%s): %s does not keep the separators of %s", t.Name(), a.alg, enc, mac)
+						break
+					}
+				}
+				if strings.ContainsAny(mac, "ABCDEF") != strings.ContainsAny(enc, "ABCDEF") || strings.ContainsAny(enc, "abcdef") && !strings.ContainsAny(mac, "abcdef0123456789") {
+					t.Errorf("%s(%s): %s does not keep the case of %s", t.Name(), a.alg, enc, mac)
+				}
+				var oui = strings.NewReplacer(":", "", "-", "", ".", "").Replace(mac)[:ouiLen]
+				if keep == KeepOUI && !strings.HasPrefix(strings.NewReplacer(":", "", "-", "", ".", "").Replace(enc), oui) {
+					t.Errorf("%s(%s): %s does not keep the OUI of %s", t.Name(), a.alg, enc, mac)
+				}
+				if macBits(enc) != macBits(mac) {
+					t.Errorf("%s(%s): %s does not keep the address bits of %s", t.Name(), a.alg, enc, mac)
+				}
+
+				var dec, errDec = p.Decrypt(enc)
+				if errDec != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, mac, errDec)
+					continue
+				}
+				if strings.Compare(dec, mac) != 0 {
+					t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, mac)
+				}
+			}
+		}
+	}
+}
+
+func TestMACCase(t *testing.T) {
+	for _, a := range configAlgorithms {
+		for _, keep := range []MACPolicy{KeepOUI, KeepAddressBits} {
+			var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: MACField, MAC: MACOptions{keep}})
+			if err != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+			}
+
+			// Addresses with a single letter, many of them are enciphered into digits only
+			for i := 0; i < 500; i++ {
+				for _, mac := range []string{fmt.Sprintf("00:11:22:%02d:%02d:AB", i/100, i%100), fmt.Sprintf("00:11:22:%02d:%02d:0c", i/100, i%100)} {
+					var enc, errEnc = p.Encrypt(mac)
+					if errEnc != nil {
+						t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, mac, errEnc)
+						continue
+					}
+					if strings.ContainsAny(mac, "ABCDEF") != strings.ContainsAny(enc, "ABCDEF") || strings.ContainsAny(enc, "abcdef") && strings.ContainsAny(mac, "ABCDEF") {
+						t.Errorf("%s(%s): %s does not keep the case of %s", t.Name(), a.alg, enc, mac)
+					}
+
+					var dec, errDec = p.Decrypt(enc)
+					if errDec != nil {
+						t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, mac, errDec)
+						continue
+					}
+					if strings.Compare(dec, mac) != 0 {
+						t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, mac)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestMACErrors(t *testing.T) {
+	var p, err = NewFpeProcessor(Config{Key: commonKey128, Field: MACField})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	for _, mac := range []string{"", "00:1A:2B:3C:4D", "00:1A:2B:3C:4D:5E:6F", "00:1G:2B:3C:4D:5E", "00 1A 2B 3C 4D 5E", "00:1A:2b:3C:4d:5E"} {
+		if _, errEnc := p.Encrypt(mac); errEnc != ErrInvalidMAC {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), mac, errEnc, ErrInvalidMAC)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// An international (E.164) phone number is composed by a 1 to 3-digit country calling
+// code and a national significant number, for 15 digits at most. In national format, the
+// number may start with a trunk prefix (0 in most countries).
+package helper
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	phoneMaxLen = 15
+	// Subscriber numbers below 10^6 values are not enciphered, as recommended by NIST
+	// SP 800-38G Rev. 1 for the minimum domain size of FF1 and FF3-1.
+	phoneMinCryptLen = 6
+)
+
+// ErrInvalidPhone is returned when a phone number contains other characters than digits,
+// spaces and the separators + - . / ( ), or has more than 15 digits.
+var ErrInvalidPhone = errors.New("helper: invalid phone number")
+
+// PhoneOptions configures the processors of a PhoneField.
+type PhoneOptions struct {
+	// KeepAreaDigits is the number of digits left in clear after the country calling
+	// code, or after the trunk prefix in national format, e.g. 3 for a NANP area code.
+	KeepAreaDigits int
+}
+
+// phoneCodec enciphers the subscriber digits of phone numbers. The international prefix
+// (+ or 00) and country calling code, or the national trunk prefix 0, are left in clear
+// with all formatting characters. Numbers with less than 6 digits to encipher are
+// rejected with ErrDomainTooSmall.
+type phoneCodec struct {
+	opts PhoneOptions
+}
+
+func newPhoneCodec(opts PhoneOptions) (*phoneCodec, error) {
+	if opts.KeepAreaDigits < 0 {
+		return nil, fmt.Errorf("newPhoneCodec: negative number of area digits %d", opts.KeepAreaDigits)
+	}
+	return &phoneCodec{opts}, nil
+}
+
+func (c *phoneCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var runes = []rune(in)
+	var numeralString = make([]uint16, 0, len(runes))
+	var international = false
+
+	// Create numeral string
+	for _, r := range runes {
+		switch {
+		case r >= '0' && r <= '9':
+			numeralString = append(numeralString, uint16(r-'0'))
+		case r == '+':
+			// The + international prefix must come before any digit
+			if international || len(numeralString) != 0 {
+				return "", ErrInvalidPhone
+			}
+			international = true
+		case r != ' ' && r != '-' && r != '.' && r != '/' && r != '(' && r != ')':
+			return "", ErrInvalidPhone
+		}
+	}
+
+	// Number of leading digits left in clear: the international prefix and the country
+	// calling code, or the trunk prefix
+	var first, ccStart = 0, -1
+	switch {
+	case international:
+		ccStart = 0
+	case len(numeralString) > 1 && numeralString[0] == 0 && numeralString[1] == 0:
+		ccStart = 2
+	case len(numeralString) > 0 && numeralString[0] == 0:
+		first = 1
+	}
+	if ccStart >= 0 {
+		var ccLen = countryCodeLen(numeralString[ccStart:])
+		if ccLen == 0 || len(numeralString)-ccStart > phoneMaxLen {
+			return "", ErrInvalidPhone
+		}
+		first = ccStart + ccLen
+	} else if len(numeralString) > phoneMaxLen {
+		return "", ErrInvalidPhone
+	}
+
+	first += c.opts.KeepAreaDigits
+	if len(numeralString)-first < phoneMinCryptLen {
+		return "", ErrDomainTooSmall
+	}
+
+	// Encrypt subscriber digits. In national format, the first digit after the trunk
+	// prefix is not 0, otherwise the ciphertext would be read as a trunk prefix or an
+	// international number.
+	var national = ccStart < 0 && c.opts.KeepAreaDigits == 0
+	var err = cryptDigits(newMode, runes, first, len(numeralString), func(subscriber []uint16) bool {
+		return !national || subscriber[0] != 0
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(runes), nil
+}
+
+// twoDigitCountryCodes are the 2-digit country calling codes. Country calling codes are a
+// prefix code: 1 and 7 are the only 1-digit codes and all the other codes have 3 digits.
+var twoDigitCountryCodes = map[uint16]bool{
+	20: true, 27: true, 30: true, 31: true, 32: true, 33: true, 34: true, 36: true, 39: true,
+	40: true, 41: true, 43: true, 44: true, 45: true, 46: true, 47: true, 48: true, 49: true,
+	51: true, 52: true, 53: true, 54: true, 55: true, 56: true, 57: true, 58: true,
+	60: true, 61: true, 62: true, 63: true, 64: true, 65: true, 66: true,
+	81: true, 82: true, 84: true, 86: true,
+	90: true, 91: true, 92: true, 93: true, 94: true, 95: true, 98: true,
+}
+
+// countryCodeLen returns the length of the country calling code at the start of the digits,
+// or 0 if there is none.
+func countryCodeLen(digits []uint16) int {
+	switch {
+	case len(digits) == 0 || digits[0] == 0:
+		return 0
+	case digits[0] == 1 || digits[0] == 7:
+		return 1
+	case len(digits) < 2:
+		return 0
+	case twoDigitCountryCodes[digits[0]*10+digits[1]]:
+		return 2
+	case len(digits) < 3:
+		return 0
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,148 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var phoneTests = []struct {
+	phone string
+	// Number of leading characters left in clear, with KeepAreaDigits set to area
+	clear int
+	area  int
+}{
+	{"+41 44 668 18 00", 4, 0},
+	{"+41 44 668 18 00", 6, 2},
+	{"+1 (415) 555-2671", 3, 0},
+	{"+1 (415) 555-2671", 8, 3},
+	{"0041 44 668 18 00", 7, 2},
+	{"+44 20 7946 0958", 4, 0},
+	{"+352 621 123 456", 5, 0},
+	{"+7 495 123-45-67", 2, 0},
+	{"044 668 18 00", 1, 0},
+	{"(415) 555-2671", 5, 3},
+	{"+33.1.23.45.67.89", 4, 0},
+	{"06/12/34/56/78", 1, 0},
+}
+
+func TestPhoneEncryptDecrypt(t *testing.T) {
+	for _, a := range configAlgorithms {
+		for _, test := range phoneTests {
+			var p, err = NewFpeProcessor(Config{
+				Key:       commonKey128,
+				Algorithm: a.alg,
+				Tweak:     a.tweak,
+				Field:     PhoneField,
+				Phone:     PhoneOptions{KeepAreaDigits: test.area},
+			})
+			if err != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+			}
+
+			var enc, errEnc = p.Encrypt(test.phone)
+			if errEnc != nil {
+				t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, test.phone, errEnc)
+				continue
+			}
+			if enc[:test.clear] != test.phone[:test.clear] {
+				t.Errorf("%s(%s): %s does not keep the prefix %q of %s", t.Name(), a.alg, enc, test.phone[:test.clear], test.phone)
+			}
+			for i := range enc {
+				if (enc[i] >= '0' && enc[i] <= '9') != (test.phone[i] >= '0' && test.phone[i] <= '9') ||
+					((test.phone[i] < '0' || test.phone[i] > '9') && enc[i] != test.phone[i]) {
+					t.Errorf("%s(%s): %s does not keep the format of %s", t.Name(), a.alg, enc, test.phone)
+					break
+				}
+			}
+
+			var dec, errDec = p.Decrypt(enc)
+			if errDec != nil {
+				t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, test.phone, errDec)
+				continue
+			}
+			if strings.Compare(dec, test.phone) != 0 {
+				t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, test.phone)
+			}
+		}
+	}
+}
+
+func TestPhoneErrors(t *testing.T) {
+	var p, err = NewFpeProcessor(Config{Key: commonKey128, Field: PhoneField, Phone: PhoneOptions{KeepAreaDigits: 2}})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	var invalidPhones = []struct {
+		phone string
+		err   error
+	}{
+		{"+41 44 668 18 00 ext. 12", ErrInvalidPhone},
+		{"41+44 668 18 00", ErrInvalidPhone},
+		{"++41 44 668 18 00", ErrInvalidPhone},
+		{"+0 44 668 18 00", ErrInvalidPhone},
+		{"+41 44 668 18 00 12 34 56", ErrInvalidPhone},
+		{"1234567890123456", ErrInvalidPhone},
+		{"+41 44 12345", ErrDomainTooSmall},
+		{"112", ErrDomainTooSmall},
+		{"", ErrDomainTooSmall},
+	}
+	for _, test := range invalidPhones {
+		if _, errEnc := p.Encrypt(test.phone); errEnc != test.err {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), test.phone, errEnc, test.err)
+		}
+	}
+
+	if _, err = NewFpeProcessor(Config{Key: commonKey128, Field: PhoneField, Phone: PhoneOptions{KeepAreaDigits: -3}}); err == nil {
+		t.Errorf("%s: negative number of area digits should be rejected", t.Name())
+	}
+}
+
+func TestCountryCodeLen(t *testing.T) {
+	var countryCodes = []struct {
+		digits []uint16
+		len    int
+	}{
+		{[]uint16{1, 4, 1, 5}, 1},
+		{[]uint16{7, 4, 9, 5}, 1},
+		{[]uint16{4, 1, 4, 4}, 2},
+		{[]uint16{8, 6, 1, 0}, 2},
+		{[]uint16{3, 5, 2, 6}, 3},
+		{[]uint16{9, 7, 1, 5}, 3},
+		{[]uint16{4, 2, 0, 6}, 3},
+		{[]uint16{0, 4, 1}, 0},
+		{[]uint16{3, 5}, 0},
+		{[]uint16{}, 0},
+	}
+	for _, test := range countryCodes {
+		if l := countryCodeLen(test.digits); l != test.len {
+			t.Errorf("%s(%v):\nhave %d\nwant %d", t.Name(), test.digits, l, test.len)
+		}
+	}
+}
+
+func TestPhoneNationalFormat(t *testing.T) {
+	var p, err = NewFpeProcessor(Config{Key: commonKey128, Tweak: commonTweak, Field: PhoneField})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	// The ciphertexts of national numbers must not look like international numbers or
+	// lose their trunk prefix, whatever the enciphered digits
+	for i := 0; i < 500; i++ {
+		for _, phone := range []string{fmt.Sprintf("0%09d", 100000000+i*1700003), fmt.Sprintf("%010d", 1000000000+i*17000003)} {
+			var enc, errEnc = p.Encrypt(phone)
+			if errEnc != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), phone, errEnc)
+			}
+			var dec, errDec = p.Decrypt(enc)
+			if errDec != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), phone, errDec)
+			}
+			if strings.Compare(dec, phone) != 0 {
+				t.Errorf("%s:\nhave %s\nwant %s", t.Name(), dec, phone)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package helper
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+)
+
+// NewFpePrefixIPProcessor returns a prefix-preserving IP address processor, as in the
+// Crypto-PAn scheme (Xu et al., "Prefix-Preserving IP Address Anonymization", 2002): two
+// addresses sharing a k-bit prefix are enciphered into addresses sharing a k-bit prefix,
+// so that subnets can still be analyzed.
+//
+// Every bit of an address is flipped or not according to the first bit of the AES
+// encryption of the preceding bits, padded with a block derived from the tweak. The AES
+// key is derived from Config.Key, so that it is not the key of the FPE processors. The
+// leading bits set by Config.IP are left in clear and addresses are written according to
+// Config.IP.Format. The field type, alphabet and radix of the configuration are ignored.
+//
+// Unlike the FPE processors, the result is not a random permutation of the addresses:
+// it reveals the length of the common prefix of any two addresses.
+func NewFpePrefixIPProcessor(c Config) (FpeProcessor, error) {
+	if c.IP.IPv4PrefixLen < 0 || c.IP.IPv4PrefixLen > 32 || c.IP.IPv6PrefixLen < 0 || c.IP.IPv6PrefixLen > 128 {
+		return nil, fmt.Errorf("NewFpePrefixIPProcessor: prefix length out of range")
+	}
+	var p, err = newFpeProcessor(c, nil)
+	if err != nil {
+		return nil, err
+	}
+	var block cipher.Block
+	if block, err = newPrefixIPBlock(c.Key); err != nil {
+		return nil, err
+	}
+	return &prefixIPProcessor{p, block, c.IP}, nil
+}
+
+// prefixIPLabel is the label of the key of the prefix-preserving processors. It is
+// followed by a block counter.
+const prefixIPLabel = "helper.prefixIP"
+
+// newPrefixIPBlock returns the AES cipher of the prefix-preserving processors. Its key is
+// made of the AES encryptions with key of prefixIPLabel followed by the counters 0 and
+// 1, truncated to the length of key.
+func newPrefixIPBlock(key []byte) (cipher.Block, error) {
+	var block, err = aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var subkey = make([]byte, 2*aes.BlockSize)
+	for i := 0; i < 2; i++ {
+		var label = subkey[i*aes.BlockSize : (i+1)*aes.BlockSize]
+		copy(label, prefixIPLabel)
+		label[aes.BlockSize-1] = byte(i)
+		block.Encrypt(label, label)
+	}
+	return aes.NewCipher(subkey[:len(key)])
+}
+
+type prefixIPProcessor struct {
+	p *fpeProcessor
+	// block is the AES cipher of the prefix-preserving encryption.
+	block cipher.Block
+	opts  IPOptions
+}
+
+func (x *prefixIPProcessor) Encrypt(in string) (string, error) {
+	return x.crypt(in, x.p.tweak, false)
+}
+
+func (x *prefixIPProcessor) Decrypt(in string) (string, error) {
+	return x.crypt(in, x.p.tweak, true)
+}
+
+func (x *prefixIPProcessor) EncryptWithTweak(ctx context.Context, in string, tweak []byte) (string, error) {
+	if _, err := x.p.modesWithTweak(ctx, tweak, false); err != nil {
+		return "", err
+	}
+	return x.crypt(in, tweak, false)
+}
+
+func (x *prefixIPProcessor) DecryptWithTweak(ctx context.Context, in string, tweak []byte) (string, error) {
+	if _, err := x.p.modesWithTweak(ctx, tweak, true); err != nil {
+		return "", err
+	}
+	return x.crypt(in, tweak, true)
+}
+
+func (x *prefixIPProcessor) crypt(in string, tweak []byte, decrypt bool) (string, error) {
+	var addr, err = netip.ParseAddr(in)
+	if err != nil {
+		return "", err
+	}
+	var pad = prefixIPPad(x.block, tweak)
+
+	switch {
+	case addr.Is4():
+		var bytes = addr.As4()
+		cryptPrefixPreserving(x.block, pad, bytes[:], x.opts.IPv4PrefixLen, decrypt)
+		return netip.AddrFrom4(bytes).String(), nil
+	case addr.Is4In6():
+		var bytes = addr.Unmap().As4()
+		cryptPrefixPreserving(x.block, pad, bytes[:], x.opts.IPv4PrefixLen, decrypt)
+		return netip.AddrFrom16(netip.AddrFrom4(bytes).As16()).WithZone(addr.Zone()).String(), nil
+	default:
+		var bytes = addr.As16()
+		cryptPrefixPreserving(x.block, pad, bytes[:], x.opts.IPv6PrefixLen, decrypt)
+		var out = netip.AddrFrom16(bytes)
+		if x.opts.Format == OriginalIPFormat {
+			return formatIPv6Like(out, in) + zoneSuffix(addr.Zone()), nil
+		}
+		return out.WithZone(addr.Zone()).String(), nil
+	}
+}
+
+// prefixIPPad returns the padding block of the tweak: the AES CBC-MAC of the tweak
+// prefixed by its 64-bit length and padded with zeros.
+func prefixIPPad(block cipher.Block, tweak []byte) []byte {
+	var msg = make([]byte, 8, 8+len(tweak)+aes.BlockSize)
+	binary.BigEndian.PutUint64(msg, uint64(len(tweak)))
+	msg = append(msg, tweak...)
+	for len(msg)%aes.BlockSize != 0 {
+		msg = append(msg, 0)
+	}
+
+	var pad = make([]byte, aes.BlockSize)
+	for i := 0; i < len(msg); i += aes.BlockSize {
+		for j := range pad {
+			pad[j] ^= msg[i+j]
+		}
+		block.Encrypt(pad, pad)
+	}
+	return pad
+}
+
+// cryptPrefixPreserving enciphers (or deciphers) the big-endian address in place, bit
+// after bit. The first prefixLen bits are left unchanged, and every following bit is
+// flipped if the first bit of the encryption of the preceding plaintext bits, followed by
+// the remaining bits of pad, is 1.
+func cryptPrefixPreserving(block cipher.Block, pad []byte, bytes []byte, prefixLen int, decrypt bool) {
+	var plaintext = make([]byte, len(bytes))
+	copy(plaintext, bytes)
+	var in = make([]byte, aes.BlockSize)
+	var out = make([]byte, aes.BlockSize)
+
+	for i := prefixLen; i < len(bytes)*8; i++ {
+		// Plaintext bits before i, then pad
+		copy(in, pad)
+		copy(in, plaintext[:i/8])
+		var mask = byte(0xff) << uint(8-i%8)
+		in[i/8] = plaintext[i/8]&mask | pad[i/8]&^mask
+		block.Encrypt(out, in)
+
+		var bit = byte(0x80) >> uint(i%8)
+		if out[0]&0x80 != 0 {
+			bytes[i/8] ^= bit
+		}
+		if decrypt {
+			// The plaintext bit is needed for the next bits
+			plaintext[i/8] = plaintext[i/8]&^bit | bytes[i/8]&bit
+		}
+	}
+}
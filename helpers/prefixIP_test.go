@@ -0,0 +1,145 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+// commonPrefixLen returns the number of leading bits shared by two addresses of the same
+// family.
+func commonPrefixLen(a, b netip.Addr) int {
+	var x, y = a.AsSlice(), b.AsSlice()
+	for i := range x {
+		if x[i] != y[i] {
+			var n = i * 8
+			for mask := byte(0x80); x[i]&mask == y[i]&mask; mask >>= 1 {
+				n++
+			}
+			return n
+		}
+	}
+	return len(x) * 8
+}
+
+func TestPrefixIPEncryptDecrypt(t *testing.T) {
+	for _, a := range configAlgorithms {
+		var p, err = NewFpePrefixIPProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak})
+		if err != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+
+		for _, ip := range ipAddresses {
+			var enc, errEnc = p.Encrypt(ip)
+			if errEnc != nil {
+				t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, ip, errEnc)
+				continue
+			}
+			var dec, errDec = p.Decrypt(enc)
+			if errDec != nil {
+				t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, ip, errDec)
+				continue
+			}
+			if strings.Compare(dec, ip) != 0 {
+				t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, ip)
+			}
+		}
+	}
+}
+
+func TestPrefixIPPreservesPrefixes(t *testing.T) {
+	var p, err = NewFpePrefixIPProcessor(Config{Key: commonKey128, Tweak: commonTweak})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	var families = [][]string{
+		{"192.168.1.1", "192.168.1.2", "192.168.2.1", "192.169.0.0", "10.0.0.1", "10.128.0.1", "0.0.0.0"},
+		{"2001:db8::1", "2001:db8::2", "2001:db8:1::1", "2001:db9::", "fe80::1", "::1"},
+	}
+	for _, ips := range families {
+		var encs = make([]netip.Addr, len(ips))
+		for i, ip := range ips {
+			var enc, errEnc = p.Encrypt(ip)
+			if errEnc != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), ip, errEnc)
+			}
+			encs[i] = netip.MustParseAddr(enc)
+		}
+
+		for i := range ips {
+			for j := range ips {
+				var want = commonPrefixLen(netip.MustParseAddr(ips[i]), netip.MustParseAddr(ips[j]))
+				if have := commonPrefixLen(encs[i], encs[j]); have != want {
+					t.Errorf("%s: %s and %s share %d bits, %s and %s share %d bits", t.Name(),
+						ips[i], ips[j], want, encs[i], encs[j], have)
+				}
+			}
+		}
+	}
+}
+
+func TestPrefixIPOptions(t *testing.T) {
+	var p, err = NewFpePrefixIPProcessor(Config{Key: commonKey128, Tweak: commonTweak,
+		IP: IPOptions{IPv4PrefixLen: 8, IPv6PrefixLen: 32, Format: OriginalIPFormat}})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	for _, ip := range []string{"10.1.2.3", "2001:0DB8:0000:0000:0000:0000:0000:0001"} {
+		var enc, errEnc = p.Encrypt(ip)
+		if errEnc != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), ip, errEnc)
+		}
+		var in, out = netip.MustParseAddr(ip), netip.MustParseAddr(enc)
+		if commonPrefixLen(in, out) < 8 || (in.Is6() && (commonPrefixLen(in, out) < 32 || len(enc) != len(ip) || strings.ToUpper(enc) != enc)) {
+			t.Errorf("%s: %s does not keep the prefix or format of %s", t.Name(), enc, ip)
+		}
+	}
+
+	// Different tweaks give different addresses
+	var enc1, _ = p.EncryptWithTweak(context.Background(), "10.1.2.3", []byte{1})
+	var enc2, _ = p.EncryptWithTweak(context.Background(), "10.1.2.3", []byte{2})
+	if enc1 == enc2 {
+		t.Errorf("%s: %s enciphered independently of the tweak", t.Name(), enc1)
+	}
+	var dec, _ = p.DecryptWithTweak(context.Background(), enc2, []byte{2})
+	if dec != "10.1.2.3" {
+		t.Errorf("%s:\nhave %s\nwant %s", t.Name(), dec, "10.1.2.3")
+	}
+
+	if _, err = NewFpePrefixIPProcessor(Config{Key: commonKey128, IP: IPOptions{IPv4PrefixLen: 33}}); err == nil {
+		t.Errorf("%s: prefix length 33 should be rejected", t.Name())
+	}
+	if _, err = p.Encrypt("10.1.2"); err == nil {
+		t.Errorf("%s: invalid address should be rejected", t.Name())
+	}
+}
+
+func TestPrefixIPKey(t *testing.T) {
+	for _, keyLen := range []int{16, 24, 32} {
+		var key = make([]byte, keyLen)
+		copy(key, commonKey128)
+		var prefixBlock, err = newPrefixIPBlock(key)
+		if err != nil {
+			t.Fatalf("%s(%d): %s", t.Name(), keyLen, err)
+		}
+		var fpeBlock, _ = aes.NewCipher(key)
+
+		// The prefix-preserving encryption does not use the key of the FPE processors
+		var in = make([]byte, aes.BlockSize)
+		var prefixOut, fpeOut = make([]byte, aes.BlockSize), make([]byte, aes.BlockSize)
+		prefixBlock.Encrypt(prefixOut, in)
+		fpeBlock.Encrypt(fpeOut, in)
+		if bytes.Equal(prefixOut, fpeOut) {
+			t.Errorf("%s(%d): the prefix-preserving key is the FPE key", t.Name(), keyLen)
+		}
+	}
+
+	if _, err := NewFpePrefixIPProcessor(Config{Key: commonKey128[:10], Algorithm: FF1}); err == nil {
+		t.Errorf("%s: invalid key length should be rejected", t.Name())
+	}
+}
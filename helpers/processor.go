@@ -0,0 +1,201 @@
+package helper
+
+import (
+	"context"
+	"crypto/cipher"
+	"fmt"
+)
+
+// FpeProcessor enciphers and deciphers field values with a single key, so that the
+// direction is explicit at the call site and a round-trip always uses the same key.
+//
+// An FpeProcessor has no mutable state: the FPE modes are created for each call, so a
+// single processor can be used concurrently from multiple goroutines, with different
+// tweaks. The tweak passed to EncryptWithTweak or DecryptWithTweak must not be modified
+// before the call returns.
+type FpeProcessor interface {
+	// Encrypt enciphers a field value with the default tweak.
+	Encrypt(in string) (string, error)
+	// Decrypt deciphers a field value with the default tweak.
+	Decrypt(in string) (string, error)
+	// EncryptWithTweak enciphers a field value with the given tweak.
+	EncryptWithTweak(ctx context.Context, in string, tweak []byte) (string, error)
+	// DecryptWithTweak deciphers a field value with the given tweak.
+	DecryptWithTweak(ctx context.Context, in string, tweak []byte) (string, error)
+}
+
+// modeFunc returns the FPE mode used to encipher (or decipher) a numeral string of the
+// given radix and length.
+type modeFunc func(radix uint32, length int) (cipher.BlockMode, error)
+
+// fieldCodec extracts the numeral strings of a field value, enciphers or deciphers them
+// with the modes returned by newMode, then formats the result back.
+type fieldCodec interface {
+	crypt(newMode modeFunc, in string) (string, error)
+}
+
+// fixedMode always returns m, whatever the radix and length. It is used by the processors
+// built around a cipher.BlockMode, where the radix is chosen by the caller.
+func fixedMode(m cipher.BlockMode) modeFunc {
+	return func(uint32, int) (cipher.BlockMode, error) {
+		return m, nil
+	}
+}
+
+type fpeProcessor struct {
+	alg   Algorithm
+	block cipher.Block
+	tweak []byte
+	codec fieldCodec
+}
+
+// NewFpeProcessor validates the configuration and returns a processor able to both
+// encipher and decipher values of the configured field type.
+func NewFpeProcessor(c Config) (FpeProcessor, error) {
+	var codec, err = newFieldCodec(c)
+	if err != nil {
+		return nil, err
+	}
+	return newFpeProcessor(c, codec)
+}
+
+func newFpeProcessor(c Config, codec fieldCodec) (*fpeProcessor, error) {
+	var block, err = newBlock(c.Algorithm, c.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkTweak(c.Algorithm, c.Tweak); err != nil {
+		return nil, err
+	}
+
+	return &fpeProcessor{
+		alg:   c.Algorithm,
+		block: block,
+		tweak: append([]byte(nil), c.Tweak...),
+		codec: codec,
+	}, nil
+}
+
+func (x *fpeProcessor) Encrypt(in string) (string, error) {
+	return x.codec.crypt(x.modes(x.tweak, false), in)
+}
+
+func (x *fpeProcessor) Decrypt(in string) (string, error) {
+	return x.codec.crypt(x.modes(x.tweak, true), in)
+}
+
+func (x *fpeProcessor) EncryptWithTweak(ctx context.Context, in string, tweak []byte) (string, error) {
+	return x.cryptWithTweak(ctx, in, tweak, false)
+}
+
+func (x *fpeProcessor) DecryptWithTweak(ctx context.Context, in string, tweak []byte) (string, error) {
+	return x.cryptWithTweak(ctx, in, tweak, true)
+}
+
+func (x *fpeProcessor) cryptWithTweak(ctx context.Context, in string, tweak []byte, decrypt bool) (string, error) {
+	var modes, err = x.modesWithTweak(ctx, tweak, decrypt)
+	if err != nil {
+		return "", err
+	}
+	return x.codec.crypt(modes, in)
+}
+
+// modesWithTweak checks the context and the tweak, and returns a modeFunc creating new FPE
+// modes with the tweak and direction.
+func (x *fpeProcessor) modesWithTweak(ctx context.Context, tweak []byte, decrypt bool) (modeFunc, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := checkTweak(x.alg, tweak); err != nil {
+		return nil, err
+	}
+	return x.modes(tweak, decrypt), nil
+}
+
+// modes returns a modeFunc creating new FPE modes with the given tweak and direction. It
+// rejects numeral strings longer than the maximum length of the algorithm.
+func (x *fpeProcessor) modes(tweak []byte, decrypt bool) modeFunc {
+	return func(radix uint32, length int) (cipher.BlockMode, error) {
+		if err := checkNumeralStringLen(x.alg, radix, length); err != nil {
+			return nil, err
+		}
+		return newMode(x.alg, x.block, tweak, radix, decrypt)
+	}
+}
+
+// newFieldCodec returns the codec of the field type selected in the configuration.
+func newFieldCodec(c Config) (fieldCodec, error) {
+	var codec fieldCodec
+	var radix uint32
+	switch c.Field {
+	case CreditCardField:
+		codec, radix = creditCardCodec{c.CreditCard}, CCRadix
+	case StringField:
+		var strCodec, err = newStringCodec(c.Alphabet)
+		if err != nil {
+			return nil, err
+		}
+		strCodec.opts = c.String
+		codec, radix = strCodec, uint32(len(strCodec.alphabetSlice))
+	case CharClassField:
+		codec, radix = charClassCodec{}, decimalRadix
+	case IBANField:
+		codec, radix = ibanCodec{}, decimalRadix
+	case SSNField:
+		codec, radix = ssnCodec{}, decimalRadix
+	case PhoneField:
+		var phCodec, err = newPhoneCodec(c.Phone)
+		if err != nil {
+			return nil, err
+		}
+		codec, radix = phCodec, decimalRadix
+	case EmailField:
+		codec, radix = emailCodec{c.Email}, uint32(len(emailLocalAlphabet))
+	case DateField:
+		var dtCodec, err = newDateCodec(c.Date)
+		if err != nil {
+			return nil, err
+		}
+		codec, radix = dtCodec, decimalRadix
+	case AmountField:
+		var amtCodec, err = newAmountCodec(c.Amount)
+		if err != nil {
+			return nil, err
+		}
+		codec, radix = amtCodec, decimalRadix
+	case IPField:
+		var ipc, err = newIPCodec(c.IP)
+		if err != nil {
+			return nil, err
+		}
+		codec, radix = ipc, decimalRadix
+	case MACField:
+		codec, radix = macCodec{c.MAC}, hexRadix
+	case CheckDigitField:
+		var cdCodec, err = newCheckDigitCodec(c.CheckDigit)
+		if err != nil {
+			return nil, err
+		}
+		codec, radix = cdCodec, decimalRadix
+	case ProductCodeField:
+		codec, radix = productCodeCodec{c.ProductCode}, decimalRadix
+	case IMEIField:
+		codec, radix = imeiCodec{}, decimalRadix
+	case RoutingNumberField:
+		codec, radix = routingNumberCodec{c.RoutingNumber}, decimalRadix
+	case AccountNumberField:
+		codec, radix = accountNumberCodec{}, decimalRadix
+	case VINField:
+		codec, radix = vinCodec{c.VIN}, decimalRadix
+	default:
+		return nil, fmt.Errorf("newFieldCodec: unknown field type %d", c.Field)
+	}
+
+	if c.Radix != 0 && c.Radix != radix {
+		return nil, fmt.Errorf("newFieldCodec: radix %d does not match alphabet size %d", c.Radix, radix)
+	}
+	if radix < minRadix || radix > maxRadix {
+		return nil, fmt.Errorf("newFieldCodec: radix %d out of range [%d, %d]", radix, minRadix, maxRadix)
+	}
+	return codec, nil
+}
@@ -0,0 +1,215 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFpeProcessorEncryptDecryptCreditCard(t *testing.T) {
+	for _, a := range configAlgorithms {
+		var p, err = NewFpeProcessor(Config{
+			Key:       commonKey128,
+			Algorithm: a.alg,
+			Tweak:     a.tweak,
+			Field:     CreditCardField,
+		})
+		if err != nil {
+			t.Errorf("%s(%s): %s", t.Name(), a.alg, err)
+			continue
+		}
+
+		for _, test := range luhnChecksumTests {
+			var enc, errEnc = p.Encrypt(test.creditcardStr)
+			if errEnc != nil {
+				t.Errorf("%s(%s): %s", t.Name(), a.alg, errEnc)
+				continue
+			}
+			if strings.Compare(enc, test.creditcardStr) == 0 {
+				t.Errorf("%s(%s): ciphertext equals plaintext %s", t.Name(), a.alg, enc)
+			}
+			var dec, errDec = p.Decrypt(enc)
+			if errDec != nil {
+				t.Errorf("%s(%s): %s", t.Name(), a.alg, errDec)
+				continue
+			}
+			if strings.Compare(dec, test.creditcardStr) != 0 {
+				t.Errorf("%s(%s): \nhave %s\nwant %s", t.Name(), a.alg, dec, test.creditcardStr)
+			}
+		}
+	}
+}
+
+func TestFpeProcessorMatchesFpeProcessors(t *testing.T) {
+	for _, test := range stringEncryptionTests {
+		var c = Config{
+			Key:       test.key,
+			Algorithm: FF3,
+			Tweak:     test.tweak,
+			Field:     StringField,
+			Alphabet:  test.alphabet,
+		}
+		var p, err = NewFpeProcessor(c)
+		if err != nil {
+			t.Errorf("%s(%s): %s", t.Name(), test.name, err)
+			continue
+		}
+
+		var enc, errEnc = p.Encrypt(test.in)
+		if errEnc != nil {
+			t.Errorf("%s(%s): %s", t.Name(), test.name, errEnc)
+			continue
+		}
+		if strings.Compare(enc, test.out) != 0 {
+			t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), test.name, enc, test.out)
+		}
+		var dec, errDec = p.Decrypt(test.out)
+		if errDec != nil {
+			t.Errorf("%s(%s): %s", t.Name(), test.name, errDec)
+			continue
+		}
+		if strings.Compare(dec, test.in) != 0 {
+			t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), test.name, dec, test.in)
+		}
+	}
+}
+
+func TestNewFpeProcessorInvalidTweak(t *testing.T) {
+	var configs = []Config{
+		{Key: commonKey128, Algorithm: FF3, Tweak: ff3CommonTweak1[:7], Field: CreditCardField},
+		{Key: commonKey128, Algorithm: FF31, Tweak: ff3CommonTweak1, Field: CreditCardField},
+	}
+
+	for _, c := range configs {
+		var _, err = NewFpeProcessor(c)
+		var tweakErr *TweakLengthError
+		if !errors.As(err, &tweakErr) {
+			t.Errorf("%s(%s): NewFpeProcessor should fail with a TweakLengthError, got %v", t.Name(), c.Algorithm, err)
+			continue
+		}
+		if tweakErr.Algorithm != c.Algorithm || tweakErr.Length != len(c.Tweak) {
+			t.Errorf("%s(%s): unexpected error %s", t.Name(), c.Algorithm, tweakErr)
+		}
+	}
+}
+
+func TestFpeProcessorWithTweak(t *testing.T) {
+	for _, a := range configAlgorithms {
+		var p, err = NewFpeProcessor(Config{
+			Key:       commonKey128,
+			Algorithm: a.alg,
+			Tweak:     a.tweak,
+			Field:     StringField,
+			Alphabet:  "0123456789",
+		})
+		if err != nil {
+			t.Errorf("%s(%s): %s", t.Name(), a.alg, err)
+			continue
+		}
+
+		var plaintext = "890121234567890000"
+		var otherTweak = make([]byte, len(a.tweak))
+		copy(otherTweak, a.tweak)
+		otherTweak[0] ^= 1
+
+		// The default tweak and an explicit copy of it give the same ciphertext
+		var enc, _ = p.Encrypt(plaintext)
+		var encDefault, errDefault = p.EncryptWithTweak(context.Background(), plaintext, a.tweak)
+		if errDefault != nil {
+			t.Errorf("%s(%s): %s", t.Name(), a.alg, errDefault)
+			continue
+		}
+		if strings.Compare(enc, encDefault) != 0 {
+			t.Errorf("%s(%s): \nhave %s\nwant %s", t.Name(), a.alg, encDefault, enc)
+		}
+
+		// Another tweak gives another ciphertext, which only deciphers with that tweak
+		var encOther, errOther = p.EncryptWithTweak(context.Background(), plaintext, otherTweak)
+		if errOther != nil {
+			t.Errorf("%s(%s): %s", t.Name(), a.alg, errOther)
+			continue
+		}
+		if strings.Compare(enc, encOther) == 0 {
+			t.Errorf("%s(%s): The ciphertexts should be different", t.Name(), a.alg)
+		}
+		var dec, errDec = p.DecryptWithTweak(context.Background(), encOther, otherTweak)
+		if errDec != nil {
+			t.Errorf("%s(%s): %s", t.Name(), a.alg, errDec)
+			continue
+		}
+		if strings.Compare(dec, plaintext) != 0 {
+			t.Errorf("%s(%s): \nhave %s\nwant %s", t.Name(), a.alg, dec, plaintext)
+		}
+	}
+}
+
+func TestFpeProcessorWithTweakErrors(t *testing.T) {
+	var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: FF3, Tweak: ff3CommonTweak1, Field: CreditCardField})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	var _, errTweak = p.EncryptWithTweak(context.Background(), "5503059576140641", []byte{0x01})
+	var tweakErr *TweakLengthError
+	if !errors.As(errTweak, &tweakErr) {
+		t.Errorf("%s: EncryptWithTweak should fail with a TweakLengthError, got %v", t.Name(), errTweak)
+	}
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	var _, errCtx = p.DecryptWithTweak(ctx, "5503059576140641", ff3CommonTweak2)
+	if errCtx != context.Canceled {
+		t.Errorf("%s:\nhave %v\nwant %v", t.Name(), errCtx, context.Canceled)
+	}
+}
+
+// TestFpeProcessorConcurrentTweaks uses a single processor from several goroutines, each
+// with its own tweak. Run it with the race detector (go test -race).
+func TestFpeProcessorConcurrentTweaks(t *testing.T) {
+	const goroutines = 16
+	const iterations = 50
+
+	for _, a := range configAlgorithms {
+		var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: CreditCardField})
+		if err != nil {
+			t.Errorf("%s(%s): %s", t.Name(), a.alg, err)
+			continue
+		}
+
+		// Compute the expected ciphertexts sequentially, one tweak per customer
+		var tweaks = make([][]byte, goroutines)
+		var expected = make([]string, goroutines)
+		for i := range tweaks {
+			tweaks[i] = make([]byte, len(a.tweak))
+			copy(tweaks[i], a.tweak)
+			tweaks[i][len(a.tweak)-1] ^= byte(i + 1)
+			expected[i], err = p.EncryptWithTweak(context.Background(), luhnChecksumTests[0].creditcardStr, tweaks[i])
+			if err != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+			}
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < goroutines; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < iterations; j++ {
+					var enc, errEnc = p.EncryptWithTweak(context.Background(), luhnChecksumTests[0].creditcardStr, tweaks[i])
+					if errEnc != nil || enc != expected[i] {
+						t.Errorf("%s(%s): \nhave %s (%v)\nwant %s", t.Name(), a.alg, enc, errEnc, expected[i])
+						return
+					}
+					var dec, errDec = p.DecryptWithTweak(context.Background(), enc, tweaks[i])
+					if errDec != nil || dec != luhnChecksumTests[0].creditcardStr {
+						t.Errorf("%s(%s): \nhave %s (%v)\nwant %s", t.Name(), a.alg, dec, errDec, luhnChecksumTests[0].creditcardStr)
+						return
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+	}
+}
@@ -0,0 +1,144 @@
+// A product code is a GTIN: an EAN-8, UPC-A (12 digits), EAN-13 or GTIN-14, whose last
+// digit is a GS1 mod 10 check digit, or an ISBN-10, whose last character is a mod 11
+// check character (0-9 or X). An ISBN-13 is an EAN-13 with the 978 or 979 prefix.
+package helper
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// GS1Mod10 is the check digit of GTINs (EAN-8, UPC-A, EAN-13, ISBN-13 and GTIN-14):
+	// the digits are weighted 3 and 1 alternately from the right.
+	GS1Mod10 CheckDigit = trailingCheck{1, gs1Mod10Check}
+	// ISBN10Mod11 is the check character of ISBN-10: the digits are weighted 10 to 2
+	// from the left, and a check value of 10 is written X.
+	ISBN10Mod11 CheckDigit = trailingCheck{1, isbn10Mod11Check}
+)
+
+// ErrInvalidProductCode is returned when a product code does not have 8, 10, 12, 13 or 14
+// digits, or has other characters than digits, spaces and dashes.
+var ErrInvalidProductCode = errors.New("helper: invalid product code")
+
+// Number of digits of the GS1 prefix of each GTIN length. The GS1 prefix of a UPC-A is
+// the GS1 prefix of its EAN-13 form without the leading 0, and the first digit of a
+// GTIN-14 is the packaging indicator.
+var gs1PrefixLen = map[int]int{
+	8:  3,
+	12: 2,
+	13: 3,
+	14: 4,
+}
+
+// ProductCodeOptions configures the processors of a ProductCodeField.
+type ProductCodeOptions struct {
+	// KeepGS1Prefix leaves the GS1 prefix of GTINs in clear, e.g. 400 of an EAN-13,
+	// and the packaging indicator of GTIN-14. The GS1 prefix of UPC-A and EAN-13 codes
+	// that are not company prefixes, e.g. 978 of an ISBN-13, is always left in clear.
+	KeepGS1Prefix bool
+}
+
+// productCodeCodec detects the type of product codes from their length, enciphers their
+// item reference and recomputes their check character. Spaces and dashes are left in
+// place. UPC-A and EAN-13 codes with a restricted GS1 prefix keep it, and the other
+// codes are not enciphered into restricted GS1 prefixes.
+type productCodeCodec struct {
+	opts ProductCodeOptions
+}
+
+func (c productCodeCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var runes = []rune(in)
+	var n = 0
+	var digits = make([]uint16, 0, len(runes))
+	for i, r := range runes {
+		switch {
+		case r >= '0' && r <= '9':
+			n++
+			digits = append(digits, uint16(r-'0'))
+		case r == 'X' && n == 9 && i == len(runes)-1:
+			// ISBN-10 check character
+			n++
+		case r != ' ' && r != '-':
+			return "", ErrInvalidProductCode
+		}
+	}
+
+	var scheme, keep = GS1Mod10, 0
+	var valid func([]uint16) bool
+	switch n {
+	case 10:
+		scheme = ISBN10Mod11
+	case 8, 14:
+		if c.opts.KeepGS1Prefix {
+			keep = gs1PrefixLen[n]
+		}
+	case 12, 13:
+		var prefixLen = gs1PrefixLen[n]
+		if c.opts.KeepGS1Prefix || restrictedGS1Prefix(digits, prefixLen) {
+			keep = prefixLen
+		} else {
+			valid = func(numeralString []uint16) bool {
+				return !restrictedGS1Prefix(numeralString, prefixLen)
+			}
+		}
+	default:
+		return "", ErrInvalidProductCode
+	}
+
+	if err := cryptCheckDigit(newMode, scheme, runes, keep, valid); err != nil {
+		return "", err
+	}
+	return string(runes), nil
+}
+
+// restrictedGS1Prefix tells if the GS1 prefix of a UPC-A (prefixLen 2) or EAN-13
+// (prefixLen 3) numeral string is not a company prefix: restricted circulation numbers
+// (02x, 04x and 2xx), ISSN (977), ISBN (978 and 979), refunds and coupons (98x and 99x).
+// The GS1 prefix of a UPC-A is read as the one of its EAN-13 form, with a leading 0.
+func restrictedGS1Prefix(numeralString []uint16, prefixLen int) bool {
+	var prefix = 0
+	for _, num := range numeralString[:prefixLen] {
+		prefix = prefix*10 + int(num)
+	}
+	switch {
+	case prefix >= 20 && prefix < 30, prefix >= 40 && prefix < 50:
+		return true
+	case prefix >= 200 && prefix < 300, prefix >= 977:
+		return true
+	}
+	return false
+}
+
+func gs1Mod10Check(payload string) (string, error) {
+	var numeralString, err = decimalNumeralString(payload)
+	if err != nil {
+		return "", err
+	}
+
+	var sum = uint16(0)
+	for i := range numeralString {
+		var num = numeralString[len(numeralString)-i-1]
+		if i%2 == 0 {
+			num *= 3
+		}
+		sum += num
+	}
+	return string(rune('0' + (10-sum%10)%10)), nil
+}
+
+func isbn10Mod11Check(payload string) (string, error) {
+	var numeralString, err = decimalNumeralString(payload)
+	if err != nil {
+		return "", err
+	}
+	if len(numeralString) != 9 {
+		return "", fmt.Errorf("isbn10Mod11Check: %d digits instead of 9", len(numeralString))
+	}
+
+	var sum = 0
+	for i, num := range numeralString {
+		sum += (10 - i) * int(num)
+	}
+	return string((digitAlphabet + "X")[(11-sum%11)%11]), nil
+}
@@ -0,0 +1,142 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var productCodes = []struct {
+	code   string
+	scheme CheckDigit
+	prefix int
+}{
+	{"0-306-40615-2", ISBN10Mod11, 0},
+	{"080442957X", ISBN10Mod11, 0},
+	{"978-0-306-40615-7", GS1Mod10, 3},
+	{"96385074", GS1Mod10, 3},
+	{"0 36000 29145 2", GS1Mod10, 2},
+	{"4006381333931", GS1Mod10, 3},
+	{"10012345678902", GS1Mod10, 4},
+}
+
+func TestProductCodeCheckDigits(t *testing.T) {
+	for _, test := range productCodes {
+		var compact = strings.NewReplacer(" ", "", "-", "").Replace(test.code)
+		if !test.scheme.Validate(compact) {
+			t.Errorf("%s: %s should be valid", t.Name(), test.code)
+		}
+	}
+
+	if check, _ := ISBN10Mod11.Compute("080442957"); check != "X" {
+		t.Errorf("%s:\nhave %s\nwant %s", t.Name(), check, "X")
+	}
+	if _, err := ISBN10Mod11.Compute("0804429"); err == nil {
+		t.Errorf("%s: ISBN-10 payload of 7 digits should be rejected", t.Name())
+	}
+}
+
+func TestProductCodeEncryptDecrypt(t *testing.T) {
+	for _, a := range configAlgorithms {
+		for _, keep := range []bool{false, true} {
+			var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: ProductCodeField,
+				ProductCode: ProductCodeOptions{KeepGS1Prefix: keep}})
+			if err != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+			}
+
+			for _, test := range productCodes {
+				var enc, errEnc = p.Encrypt(test.code)
+				if errEnc != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, test.code, errEnc)
+					continue
+				}
+
+				var compact, encCompact = strings.NewReplacer(" ", "", "-", "").Replace(test.code), strings.NewReplacer(" ", "", "-", "").Replace(enc)
+				if len(enc) != len(test.code) || len(encCompact) != len(compact) || !test.scheme.Validate(encCompact) {
+					t.Errorf("%s(%s): %s is not a valid product code with the format of %s", t.Name(), a.alg, enc, test.code)
+				}
+				if keep && encCompact[:test.prefix] != compact[:test.prefix] {
+					t.Errorf("%s(%s): %s does not keep the GS1 prefix of %s", t.Name(), a.alg, enc, test.code)
+				}
+
+				var dec, errDec = p.Decrypt(enc)
+				if errDec != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, test.code, errDec)
+					continue
+				}
+				if strings.Compare(dec, test.code) != 0 {
+					t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, test.code)
+				}
+			}
+		}
+	}
+}
+
+func TestProductCodeErrors(t *testing.T) {
+	var p, err = NewFpeProcessor(Config{Key: commonKey128, Field: ProductCodeField})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	var errorTests = []struct {
+		code string
+		err  error
+	}{
+		{"", ErrInvalidProductCode},
+		{"12345", ErrInvalidProductCode},
+		{"036000291452123", ErrInvalidProductCode},
+		{"ISBN 0-306-40615-2", ErrInvalidProductCode},
+		{"0-306-40615-X", ErrInvalidCheckDigit},
+		{"03X6406152", ErrInvalidProductCode},
+		{"036000291453", ErrInvalidCheckDigit},
+		{"9780306406158", ErrInvalidCheckDigit},
+		{"9783161484101", ErrInvalidCheckDigit},
+	}
+	for _, test := range errorTests {
+		if _, errEnc := p.Encrypt(test.code); errEnc != test.err {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), test.code, errEnc, test.err)
+		}
+	}
+}
+
+func TestProductCodeRestrictedPrefix(t *testing.T) {
+	var restricted = []string{"978-3-16-148410-0", "979-10-90636-07-1", "2001234567893", "9771234567003", "212345678992"}
+	for _, a := range configAlgorithms {
+		var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: ProductCodeField})
+		if err != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+
+		for _, code := range restricted {
+			var enc, errEnc = p.Encrypt(code)
+			if errEnc != nil {
+				t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, code, errEnc)
+				continue
+			}
+			var prefixLen = len(code) - 10
+			if strings.HasPrefix(code, "97") {
+				prefixLen = 3
+			}
+			if enc[:prefixLen] != code[:prefixLen] {
+				t.Errorf("%s(%s): %s does not keep the GS1 prefix of %s", t.Name(), a.alg, enc, code)
+			}
+		}
+
+		// Codes with a company prefix are not enciphered into restricted prefixes
+		for i := 0; i < 50; i++ {
+			for _, payload := range []string{fmt.Sprintf("5%011d", i*7919), fmt.Sprintf("7%010d", i*7919)} {
+				var check, _ = GS1Mod10.Compute(payload)
+				var enc, errEnc = p.Encrypt(payload + check)
+				if errEnc != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, payload+check, errEnc)
+					continue
+				}
+				var ns, _ = decimalNumeralString(enc)
+				if restrictedGS1Prefix(ns, len(enc)-10) {
+					t.Errorf("%s(%s): %s is enciphered into the restricted GS1 prefix of %s", t.Name(), a.alg, payload+check, enc)
+				}
+			}
+		}
+	}
+}
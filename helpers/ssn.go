@@ -0,0 +1,55 @@
+// A US Social Security Number is composed by a three-digit area number, a two-digit group
+// number and a four-digit serial number, usually written AAA-GG-SSSS.
+package helper
+
+import (
+	"errors"
+)
+
+const ssnLen = 9
+
+// ErrInvalidSSN is returned when a Social Security Number does not have 9 digits separated
+// by dashes or spaces, or does not honor the SSA validity rules.
+var ErrInvalidSSN = errors.New("helper: invalid Social Security Number")
+
+// ssnCodec enciphers Social Security Numbers into valid Social Security Numbers, leaving
+// the separators in place.
+type ssnCodec struct{}
+
+func (c ssnCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var runes = []rune(in)
+	var numeralString = make([]uint16, 0, ssnLen)
+
+	// Create numeral string
+	for _, r := range runes {
+		switch {
+		case r >= '0' && r <= '9':
+			if len(numeralString) == ssnLen {
+				return "", ErrInvalidSSN
+			}
+			numeralString = append(numeralString, uint16(r-'0'))
+		case r != '-' && r != ' ':
+			return "", ErrInvalidSSN
+		}
+	}
+	if len(numeralString) != ssnLen || !validSSN(numeralString) {
+		return "", ErrInvalidSSN
+	}
+
+	if err := cryptDigits(newMode, runes, 0, ssnLen, validSSN); err != nil {
+		return "", err
+	}
+
+	return string(runes), nil
+}
+
+// validSSN tells if the 9 digits of a Social Security Number honor the SSA rules: the area
+// number is not 000, 666 or in 900-999, the group number is not 00 and the serial number
+// is not 0000.
+func validSSN(numeralString []uint16) bool {
+	var area = numeralString[0]*100 + numeralString[1]*10 + numeralString[2]
+	var group = numeralString[3]*10 + numeralString[4]
+	var serial = numeralString[5]*1000 + numeralString[6]*100 + numeralString[7]*10 + numeralString[8]
+
+	return area != 0 && area != 666 && area < 900 && group != 0 && serial != 0
+}
@@ -0,0 +1,65 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSSNEncryptDecrypt(t *testing.T) {
+	for _, a := range configAlgorithms {
+		var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: SSNField})
+		if err != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+
+		for _, ssn := range []string{"078-05-1120", "123 45 6789", "665019999", "899-99-0001", "001-01-0001", "667-12-3456"} {
+			// Encrypt several times to go through the cycle-walking
+			var enc = ssn
+			for i := 0; i < 20; i++ {
+				var errEnc error
+				enc, errEnc = p.Encrypt(enc)
+				if errEnc != nil {
+					t.Fatalf("%s(%s, %s): %s", t.Name(), a.alg, ssn, errEnc)
+				}
+
+				var numeralString = make([]uint16, 0, ssnLen)
+				for j := range enc {
+					if enc[j] >= '0' && enc[j] <= '9' {
+						numeralString = append(numeralString, uint16(enc[j]-'0'))
+					} else if enc[j] != ssn[j] {
+						t.Errorf("%s(%s): %s does not keep the separators of %s", t.Name(), a.alg, enc, ssn)
+					}
+				}
+				if !validSSN(numeralString) {
+					t.Errorf("%s(%s): %s is not a valid SSN", t.Name(), a.alg, enc)
+				}
+			}
+
+			var dec = enc
+			for i := 0; i < 20; i++ {
+				var errDec error
+				dec, errDec = p.Decrypt(dec)
+				if errDec != nil {
+					t.Fatalf("%s(%s, %s): %s", t.Name(), a.alg, ssn, errDec)
+				}
+			}
+			if strings.Compare(dec, ssn) != 0 {
+				t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, ssn)
+			}
+		}
+	}
+}
+
+func TestSSNErrors(t *testing.T) {
+	var p, err = NewFpeProcessor(Config{Key: commonKey128, Field: SSNField})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	for _, ssn := range []string{"", "078-05-112", "078-05-11200", "078/05/1120", "000-12-3456", "666-12-3456",
+		"900-12-3456", "999-12-3456", "123-00-4567", "123-45-0000"} {
+		if _, errEnc := p.Encrypt(ssn); errEnc != ErrInvalidSSN {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), ssn, errEnc, ErrInvalidSSN)
+		}
+	}
+}
@@ -5,79 +5,255 @@ import (
 	"fmt"
 	"bytes"
 	"crypto/cipher"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalization is the Unicode normalization form applied to the input of a StringField.
+type Normalization int
+
+const (
+	// NoNormalization leaves the input unchanged.
+	NoNormalization Normalization = iota
+	// NFC composes the input, e.g. "e" followed by U+0301 becomes "é".
+	NFC
+	// NFD decomposes the input, e.g. "é" becomes "e" followed by U+0301.
+	NFD
 )
 
+// StringOptions configures the processors of a StringField.
+type StringOptions struct {
+	// Normalization is applied to the input before it is enciphered or deciphered, so
+	// that a character typed either composed or decomposed is found in the alphabet. The
+	// alphabet is used as is and must contain the characters of the normalized input.
+	Normalization Normalization
+	// Passthrough leaves the characters that are not in the alphabet in place, instead of
+	// returning an error, and only enciphers the characters of the alphabet. An input
+	// without any character of the alphabet is returned unchanged, and an input whose
+	// characters of the alphabet have less than 100 possible values is rejected with
+	// ErrDomainTooSmall.
+	Passthrough bool
+}
+
 type FpeString interface {
 	// Crypt encrypts or decrypts a string.
 	Crypt(in string) (string, error)
-	SetTweak(tweak []byte)
+	// SetTweak changes the tweak of the underlying mode. It returns ErrTweakNotSupported
+	// if the mode has no SetTweak function, and a *TweakLengthError if the processor knows
+	// the algorithm of the mode and the tweak length is invalid. It must not be called
+	// while Crypt is running, use FpeProcessor for per-call tweaks.
+	SetTweak(tweak []byte) error
 }
 
 type strFpe struct {
 	m	          cipher.BlockMode
+	// tweaker is nil if the mode cannot be tweaked.
+	tweaker       fpeWithSetTweak
+	// alg is unknownAlgorithm if the processor was not given the algorithm of m.
+	alg           Algorithm
+	stringCodec
+}
+
+func newFPEString(m cipher.BlockMode, alphabet string) (*strFpe, error) {
+	var codec, err = newStringCodec(alphabet)
+	var tweaker, _ = m.(fpeWithSetTweak)
+
+	return &strFpe{
+		m:         		m,
+		tweaker:		tweaker,
+		alg:			unknownAlgorithm,
+		stringCodec:	*codec,
+	}, err
+}
+
+// stringCodec enciphers strings made of characters taken from an alphabet.
+type stringCodec struct {
 	alphabetMap   map[rune]uint16
 	alphabetSlice []rune
+	opts          StringOptions
 }
 
-func newFPEString(m cipher.BlockMode, alphabet string) *strFpe {
-	var alphabetSize = len(alphabet)
+// newStringCodec returns the codec of the alphabet. The codec is returned even if the
+// alphabet is invalid, since NewFpeStringProcessor ignores the error.
+func newStringCodec(alphabet string) (*stringCodec, error) {
+	var alphabetSize = utf8.RuneCountInString(alphabet)
 	var alphabetMap = make(map[rune]uint16)
 	var alphabetSlice = make([]rune, alphabetSize)
 
-	SetAlphabet(alphabetMap, alphabetSlice, alphabet)
-
-	return &strFpe{
-		m:         		m,
-		alphabetMap:	alphabetMap,
-		alphabetSlice: 	alphabetSlice,
+	var err error
+	switch {
+	case alphabetSize < minRadix:
+		err = fmt.Errorf("newStringCodec: alphabet must have at least %d characters, got %d", minRadix, alphabetSize)
+	case alphabetSize > maxRadix:
+		err = fmt.Errorf("newStringCodec: alphabet must have at most %d characters, got %d", maxRadix, alphabetSize)
+	default:
+		err = SetAlphabet(alphabetMap, alphabetSlice, alphabet)
 	}
+
+	return &stringCodec{
+		alphabetMap:   alphabetMap,
+		alphabetSlice: alphabetSlice,
+	}, err
 }
 
 type fpeStringProcessor strFpe
 
+// NewFpeStringProcessor returns a string processor over the alphabet. An invalid alphabet
+// is silently accepted, use NewCheckedFpeStringProcessor to get the error. The plain
+// constructors do not know the algorithm of m, so SetTweak cannot check tweak lengths.
 func NewFpeStringProcessor(m cipher.BlockMode, alphabet string) FpeString {
-	return (*fpeStringProcessor)(newFPEString(m, alphabet))
+	var x, _ = newFPEString(m, alphabet)
+	return (*fpeStringProcessor)(x)
+}
+
+// NewCheckedFpeStringProcessor returns a string processor over the alphabet, or an error
+// if the alphabet has duplicate characters, less than 2 characters or more characters
+// than the 2^16 radix supported by FF1 and FF3. The radix of m must be the number of
+// characters in the alphabet.
+func NewCheckedFpeStringProcessor(m cipher.BlockMode, alphabet string) (FpeString, error) {
+	var x, err = newFPEString(m, alphabet)
+	if err != nil {
+		return nil, err
+	}
+	return (*fpeStringProcessor)(x), nil
+}
+
+// NewFpeStringProcessorWithOptions returns a string processor over the alphabet,
+// configured as the processors of a StringField. Invalid alphabets are rejected as with
+// NewCheckedFpeStringProcessor.
+func NewFpeStringProcessorWithOptions(m cipher.BlockMode, alphabet string, opts StringOptions) (FpeString, error) {
+	var x, err = newFPEString(m, alphabet)
+	if err != nil {
+		return nil, err
+	}
+	x.opts = opts
+	return (*fpeStringProcessor)(x), nil
+}
+
+// NewFpeStringProcessorWithAlgorithm is NewFpeStringProcessorWithOptions for a mode m of
+// algorithm alg, whose SetTweak rejects tweaks of invalid length. For FF31, m is an FF3
+// mode and SetTweak takes 7-byte FF3-1 tweaks.
+func NewFpeStringProcessorWithAlgorithm(m cipher.BlockMode, alg Algorithm, alphabet string, opts StringOptions) (FpeString, error) {
+	if !validAlgorithm(alg) {
+		return nil, fmt.Errorf("NewFpeStringProcessorWithAlgorithm: unknown algorithm %s", alg)
+	}
+	var x, err = newFPEString(m, alphabet)
+	if err != nil {
+		return nil, err
+	}
+	x.alg = alg
+	x.opts = opts
+	return (*fpeStringProcessor)(x), nil
 }
 
 
 func (x *fpeStringProcessor) Crypt(in string) (string, error) {
-	var numeralString, err = toNumeralString(x.alphabetMap, in)
+	return x.stringCodec.crypt(fixedMode(x.m), in)
+}
+
+func (c *stringCodec) crypt(newMode modeFunc, in string) (string, error) {
+	switch c.opts.Normalization {
+	case NFC:
+		in = norm.NFC.String(in)
+	case NFD:
+		in = norm.NFD.String(in)
+	}
+
+	if c.opts.Passthrough {
+		return c.cryptPassthrough(newMode, in)
+	}
+
+	var numeralString, err = toNumeralString(c.alphabetMap, in)
 	if err != nil {
 		return "", err
 	}
+	var m, errMode = newMode(uint32(len(c.alphabetSlice)), len(numeralString))
+	if errMode != nil {
+		return "", errMode
+	}
 
 	var b = fpe.NumeralStringToBytes(numeralString)
-	x.m.CryptBlocks(b, b)
+	m.CryptBlocks(b, b)
 	numeralString = fpe.BytesToNumeralString(b)
 
-	return fromNumeralString(x.alphabetSlice, numeralString)
+	return fromNumeralString(c.alphabetSlice, numeralString)
 }
 
+// cryptPassthrough enciphers the characters of the alphabet and leaves the others in place.
+func (c *stringCodec) cryptPassthrough(newMode modeFunc, in string) (string, error) {
+	var runes = []rune(in)
+	var numeralString = make([]uint16, 0, len(runes))
+
+	// Create numeral string
+	for _, r := range runes {
+		if num, ok := c.alphabetMap[r]; ok {
+			numeralString = append(numeralString, num)
+		}
+	}
+	if len(numeralString) == 0 {
+		return in, nil
+	}
+	if !c.largeDomain(len(numeralString)) {
+		return "", ErrDomainTooSmall
+	}
+	var m, err = newMode(uint32(len(c.alphabetSlice)), len(numeralString))
+	if err != nil {
+		return "", err
+	}
+
+	// Encrypt numeral string
+	var b = fpe.NumeralStringToBytes(numeralString)
+	m.CryptBlocks(b, b)
+	numeralString = fpe.BytesToNumeralString(b)
+
+	var numStrIdx = 0
+	// Copy enciphered data back to runes
+	for i, r := range runes {
+		if _, ok := c.alphabetMap[r]; ok {
+			runes[i] = c.alphabetSlice[numeralString[numStrIdx]]
+			numStrIdx++
+		}
+	}
+
+	return string(runes), nil
+}
+
+// largeDomain tells if n characters of the alphabet have at least minDomainSize possible
+// values.
+func (c *stringCodec) largeDomain(n int) bool {
+	var domainSize = 1
+	for i := 0; i < n && domainSize < minDomainSize; i++ {
+		domainSize *= len(c.alphabetSlice)
+	}
+	return domainSize >= minDomainSize
+}
+
+// SetAlphabet fills dstMap and dstSlice with the characters of the alphabet. dstSlice
+// must have one element per character (rune) of the alphabet.
 func SetAlphabet(dstMap map[rune]uint16, dstSlice []rune, alphabet string) (error){
-	for i, c := range alphabet {
+	var i = 0
+	for _, c := range alphabet {
 		dstSlice[i] = c
 		_, duplicateKey := dstMap[c]
 		if duplicateKey {
 			return fmt.Errorf("Duplicate character %q at index %d", c, i)
 		}
 		dstMap[c] = uint16(i)
+		i++
 	}
 	return nil
 }
 
-func (x *fpeStringProcessor) SetTweak(tweak []byte) {
-	var fpeModeWithSetTweak, ok = x.m.(fpeWithSetTweak)
-	if !ok {
-		panic("fpeStringProcessor/SetTweak: BlockMode must have a SetTweak function.")
-	}
-	fpeModeWithSetTweak.SetTweak(tweak)
+func (x *fpeStringProcessor) SetTweak(tweak []byte) error {
+	return setModeTweak(x.tweaker, x.alg, tweak)
 }
 
 func fromNumeralString(alphabetSlice []rune, numeralString []uint16) (string, error) {
 	var out bytes.Buffer
-	var alphabetSize = uint16(len(alphabetSlice))
+	var alphabetSize = len(alphabetSlice)
 	for i, num := range numeralString {
-		if num < 0 || num >= alphabetSize {
+		if int(num) >= alphabetSize {
 			return "", fmt.Errorf("fromNumeralString: Numeral %d at index %d not in alphabet range", num, i)
 		}
 		out.WriteRune(alphabetSlice[num])
@@ -86,15 +262,15 @@ func fromNumeralString(alphabetSlice []rune, numeralString []uint16) (string, er
 }
 
 func toNumeralString(alphabetMap map[rune]uint16, str string) ([]uint16, error) {
-	var strSize = len(str)
-	var numeralString = make([]uint16, strSize)
+	var strSize = utf8.RuneCountInString(str)
+	var numeralString = make([]uint16, 0, strSize)
 
-	for i, r := range str {
+	for _, r := range str {
 		_, validKey := alphabetMap[r]
 		if !validKey {
-			return nil, fmt.Errorf("toNumeralString: Character '%q' at index %d not in alphabet", r, i)
+			return nil, fmt.Errorf("toNumeralString: Character '%q' at index %d not in alphabet", r, len(numeralString))
 		}
-		numeralString[i] = alphabetMap[r]
+		numeralString = append(numeralString, alphabetMap[r])
 	}
 
 	return numeralString, nil
@@ -6,6 +6,7 @@ import (
 	"crypto/aes"
 	"crypto-fpe/fpe"
 	"reflect"
+	"unicode/utf8"
 )
 
 var ff3CommonTweak1 = []byte{0xd8, 0xe7, 0x92, 0x0a, 0xfa, 0x33, 0x0a, 0x73}
@@ -363,7 +364,7 @@ func TestToNumeralString(t *testing.T) {
 				t.Error(err)
 			}
 			if !reflect.DeepEqual(numStr, test.numeralString) {
-				t.Errorf("ToNumeralString\nhave %s\nwant %s", numStr, test.numeralString)
+				t.Errorf("ToNumeralString\nhave %v\nwant %v", numStr, test.numeralString)
 			}
 		} else {
 			if err == nil {
@@ -372,3 +373,226 @@ func TestToNumeralString(t *testing.T) {
 		}
 	}
 }
+
+// Latin letters with the accented letters used in European names, and the apostrophe,
+// hyphen and space found in compound names.
+const europeanNamesAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ" +
+	"àáâãäåæçèéêëìíîïñòóôõöøùúûüýÿßčćđšžłńśźżőűÀÁÂÃÄÅÆÇÈÉÊËÌÍÎÏÑÒÓÔÕÖØÙÚÛÜÝČĆĐŠŽŁŃŚŹŻŐŰ' -"
+
+var europeanNames = []string{
+	"François", "Müller", "Gößmann", "Đorđević", "Łukasz Żółć", "Søren Kierkegaard",
+	"José María", "Zoë O'Brien", "Jean-Étienne", "Åsa Öberg", "Ferenc Erdős",
+}
+
+func TestStringUnicodeAlphabet(t *testing.T) {
+	for _, a := range configAlgorithms {
+		var p, err = NewFpeProcessor(Config{
+			Key:       commonKey128,
+			Algorithm: a.alg,
+			Tweak:     a.tweak,
+			Field:     StringField,
+			Alphabet:  europeanNamesAlphabet,
+		})
+		if err != nil {
+			t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+		}
+
+		for _, name := range europeanNames {
+			var enc, errEnc = p.Encrypt(name)
+			if errEnc != nil {
+				t.Errorf("%s(%s): %s", t.Name(), a.alg, errEnc)
+				continue
+			}
+			if utf8.RuneCountInString(enc) != utf8.RuneCountInString(name) {
+				t.Errorf("%s(%s): %q and %q have different lengths", t.Name(), a.alg, enc, name)
+			}
+			var dec, errDec = p.Decrypt(enc)
+			if errDec != nil {
+				t.Errorf("%s(%s): %s", t.Name(), a.alg, errDec)
+				continue
+			}
+			if strings.Compare(dec, name) != 0 {
+				t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, name)
+			}
+		}
+	}
+}
+
+func TestStringNormalization(t *testing.T) {
+	// "Zoë Ångström" typed with combining diaeresis and ring above
+	var decomposed = "Zoe\u0308 A\u030angstro\u0308m"
+	var composed = "Zoë Ångström"
+
+	var p, err = NewFpeProcessor(Config{
+		Key:       commonKey128,
+		Tweak:     commonTweak,
+		Field:     StringField,
+		Alphabet:  europeanNamesAlphabet,
+		String:    StringOptions{Normalization: NFC},
+	})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	var enc, errEnc = p.Encrypt(decomposed)
+	if errEnc != nil {
+		t.Fatalf("%s: %s", t.Name(), errEnc)
+	}
+	var encComposed, _ = p.Encrypt(composed)
+	if strings.Compare(enc, encComposed) != 0 {
+		t.Errorf("%s: composed and decomposed inputs should give the same ciphertext\nhave %s\nwant %s", t.Name(), enc, encComposed)
+	}
+	var dec, errDec = p.Decrypt(enc)
+	if errDec != nil {
+		t.Fatalf("%s: %s", t.Name(), errDec)
+	}
+	if strings.Compare(dec, composed) != 0 {
+		t.Errorf("%s:\nhave %s\nwant %s", t.Name(), dec, composed)
+	}
+
+	// Without normalization, the combining characters are not in the alphabet
+	p, _ = NewFpeProcessor(Config{Key: commonKey128, Tweak: commonTweak, Field: StringField, Alphabet: europeanNamesAlphabet})
+	if _, errEnc = p.Encrypt(decomposed); errEnc == nil {
+		t.Errorf("%s: Encrypt should fail on combining characters", t.Name())
+	}
+
+	// Processors of a BlockMode
+	var aesBlock, _ = aes.NewCipher(ff3CommonKey128)
+	var radix = uint32(utf8.RuneCountInString(europeanNamesAlphabet))
+	var encrypter, errProc = NewFpeStringProcessorWithOptions(fpe.NewFF3Encrypter(aesBlock, ff3CommonTweak1, radix), europeanNamesAlphabet,
+		StringOptions{Normalization: NFC})
+	if errProc != nil {
+		t.Fatalf("%s: %s", t.Name(), errProc)
+	}
+	var decrypter, _ = NewFpeStringProcessorWithOptions(fpe.NewFF3Decrypter(aesBlock, ff3CommonTweak1, radix), europeanNamesAlphabet,
+		StringOptions{Normalization: NFC})
+	if enc, errEnc = encrypter.Crypt(decomposed); errEnc != nil {
+		t.Fatalf("%s: %s", t.Name(), errEnc)
+	}
+	if dec, _ = decrypter.Crypt(enc); strings.Compare(dec, composed) != 0 {
+		t.Errorf("%s:\nhave %s\nwant %s", t.Name(), dec, composed)
+	}
+	if _, errProc = NewFpeStringProcessorWithOptions(fpe.NewFF3Encrypter(aesBlock, ff3CommonTweak1, radix), "a", StringOptions{}); errProc == nil {
+		t.Errorf("%s: invalid alphabet should be rejected", t.Name())
+	}
+}
+
+func TestToNumeralStringUnicode(t *testing.T) {
+	var alphabet = "абвгдежзийклмнопрстуфхцчшщъыьэюя日本語"
+	var alphabetMap = make(map[rune]uint16)
+	var alphabetSlice = make([]rune, utf8.RuneCountInString(alphabet))
+	if err := SetAlphabet(alphabetMap, alphabetSlice, alphabet); err != nil {
+		t.Fatalf("SetAlphabet: %s", err)
+	}
+
+	var str = "привет日本語"
+	var numStr, err = toNumeralString(alphabetMap, str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(numStr) != utf8.RuneCountInString(str) {
+		t.Errorf("ToNumeralString\nhave %d numerals\nwant %d", len(numStr), utf8.RuneCountInString(str))
+	}
+	var back, errBack = fromNumeralString(alphabetSlice, numStr)
+	if errBack != nil {
+		t.Fatal(errBack)
+	}
+	if strings.Compare(back, str) != 0 {
+		t.Errorf("FromNumeralString\nhave %s\nwant %s", back, str)
+	}
+}
+
+func TestNewCheckedFpeStringProcessor(t *testing.T) {
+	var aesBlock, err = aes.NewCipher(ff3CommonKey128)
+	if err != nil {
+		t.Fatalf("%s: NewCipher = %s", t.Name(), err)
+	}
+
+	var hugeAlphabet = make([]rune, maxRadix+1)
+	for i := range hugeAlphabet {
+		hugeAlphabet[i] = rune(0x10000 + i)
+	}
+
+	var alphabets = []struct {
+		alphabet string
+		valid    bool
+	}{
+		{"0123456789", true},
+		{"01", true},
+		{"éèàù", true},
+		{"abca", false},
+		{"éèé", false},
+		{"", false},
+		{"a", false},
+		{string(hugeAlphabet), false},
+	}
+
+	for _, test := range alphabets {
+		var encrypter = fpe.NewFF3Encrypter(aesBlock, ff3CommonTweak1, uint32(utf8.RuneCountInString(test.alphabet)))
+		var processor, err = NewCheckedFpeStringProcessor(encrypter, test.alphabet)
+		if test.valid && (err != nil || processor == nil) {
+			t.Errorf("%s(%q): %v", t.Name(), test.alphabet, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s(%.10q): NewCheckedFpeStringProcessor should fail", t.Name(), test.alphabet)
+		}
+
+		var _, errConfig = NewFpeProcessor(Config{Key: ff3CommonKey128, Field: StringField, Alphabet: test.alphabet})
+		if test.valid != (errConfig == nil) {
+			t.Errorf("%s(%.10q): NewFpeProcessor error %v", t.Name(), test.alphabet, errConfig)
+		}
+	}
+}
+
+func TestStringPassthrough(t *testing.T) {
+	var p, err = NewFpeProcessor(Config{
+		Key:       commonKey128,
+		Tweak:     commonTweak,
+		Field:     StringField,
+		Alphabet:  "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+		String:    StringOptions{Passthrough: true},
+	})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	for _, in := range []string{"AB-1234/XY", "REF 2024.001", "-/.", "ÉCOLE-42", "Q1/Q2"} {
+		var enc, errEnc = p.Encrypt(in)
+		if errEnc != nil {
+			t.Errorf("%s: %s", t.Name(), errEnc)
+			continue
+		}
+		var dec, errDec = p.Decrypt(enc)
+		if errDec != nil {
+			t.Errorf("%s: %s", t.Name(), errDec)
+			continue
+		}
+		if strings.Compare(dec, in) != 0 {
+			t.Errorf("%s:\nhave %s\nwant %s", t.Name(), dec, in)
+		}
+
+		// Characters outside of the alphabet are kept in place
+		var inRunes, encRunes = []rune(in), []rune(enc)
+		if len(inRunes) != len(encRunes) {
+			t.Errorf("%s: %q and %q have different lengths", t.Name(), enc, in)
+			continue
+		}
+		for i, r := range inRunes {
+			if !strings.ContainsRune("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ", r) && encRunes[i] != r {
+				t.Errorf("%s: %q does not keep %q at index %d of %q", t.Name(), enc, r, i, in)
+			}
+		}
+	}
+
+	// Less than 100 possible values for the characters of the alphabet
+	var digits, _ = NewFpeProcessor(Config{Key: commonKey128, Tweak: commonTweak, Field: StringField, Alphabet: "0123456789",
+		String: StringOptions{Passthrough: true}})
+	for _, in := range []string{"AB-1/XY", "7"} {
+		if _, errEnc := digits.Encrypt(in); errEnc != ErrDomainTooSmall {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), in, errEnc, ErrDomainTooSmall)
+		}
+	}
+	if enc, errEnc := digits.Encrypt("AB-12/XY"); errEnc != nil || len(enc) != len("AB-12/XY") {
+		t.Errorf("%s(%s): %q, %v", t.Name(), "AB-12/XY", enc, errEnc)
+	}
+}
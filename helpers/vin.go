@@ -0,0 +1,137 @@
+// A Vehicle Identification Number (ISO 3779) has 17 characters among digits and letters
+// other than I, O and Q. It is composed by the World Manufacturer Identifier (WMI,
+// positions 1-3), the vehicle descriptor section (positions 4-8), a check digit
+// (position 9), the model year (position 10), the plant code (position 11) and the serial
+// number (positions 12-17).
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	vinLen       = 17
+	wmiLen       = 3
+	vinCheckIdx  = 8
+	vinYearIdx   = 9
+	vinSerialIdx = 11
+	vinLetters   = "ABCDEFGHJKLMNPRSTUVWXYZ"
+	vinAlphabet  = digitAlphabet + vinLetters
+	// Model year codes, without U, Z and 0
+	vinYearAlphabet = "ABCDEFGHJKLMNPRSTVWXY123456789"
+)
+
+var (
+	vinCharCodec, _   = newStringCodec(vinAlphabet)
+	vinLetterCodec, _ = newStringCodec(vinLetters)
+	vinYearCodec, _   = newStringCodec(vinYearAlphabet)
+)
+
+// VINCheck is the check digit of VINs at position 9, 0-9 or X: the characters are
+// transliterated to digits and weighted by their position, modulo 11.
+var VINCheck CheckDigit = vinCheck{}
+
+// ErrInvalidVIN is returned when a VIN does not have 17 uppercase characters of the VIN
+// alphabet, or has an invalid model year.
+var ErrInvalidVIN = errors.New("helper: invalid VIN")
+
+// VINOptions configures the processors of a VINField.
+type VINOptions struct {
+	// KeepModelYear leaves the model year (position 10) in clear.
+	KeepModelYear bool
+}
+
+var (
+	// Transliteration of the VIN alphabet
+	vinValues  = [...]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 7, 9, 2, 3, 4, 5, 6, 7, 8, 9}
+	vinWeights = [...]int{8, 7, 6, 5, 4, 3, 2, 10, 9, 8, 7, 6, 5, 4, 3, 2}
+)
+
+type vinCheck struct{}
+
+// Compute returns the check digit of the 16 other characters of a VIN.
+func (c vinCheck) Compute(payload string) (string, error) {
+	if len(payload) != vinLen-1 {
+		return "", fmt.Errorf("vinCheck: %d characters instead of %d", len(payload), vinLen-1)
+	}
+
+	var sum = 0
+	for i, r := range payload {
+		var v = strings.IndexRune(vinAlphabet, r)
+		if v < 0 {
+			return "", fmt.Errorf("vinCheck: Character %q at index %d not in alphabet", r, i)
+		}
+		sum += vinValues[v] * vinWeights[i]
+	}
+	return string((digitAlphabet + "X")[sum%11]), nil
+}
+
+func (c vinCheck) Validate(s string) bool {
+	return validateCheckDigit(c, s)
+}
+
+func (c vinCheck) Position(n int) (int, int) {
+	return vinCheckIdx, vinCheckIdx + 1
+}
+
+// vinCodec enciphers the characters of VINs after the WMI, and recomputes the check
+// digit. The vehicle descriptor section and the plant code take any character of the VIN
+// alphabet, the model year any model year code, and the serial number keeps its letters
+// and digits in their class. VINs with an invalid check digit are rejected with
+// ErrInvalidCheckDigit.
+type vinCodec struct {
+	opts VINOptions
+}
+
+func (c vinCodec) crypt(newMode modeFunc, in string) (string, error) {
+	var runes = []rune(in)
+	if len(runes) != vinLen {
+		return "", ErrInvalidVIN
+	}
+	for _, r := range runes {
+		if strings.IndexRune(vinAlphabet, r) < 0 {
+			return "", ErrInvalidVIN
+		}
+	}
+	if !VINCheck.Validate(in) {
+		return "", ErrInvalidCheckDigit
+	}
+
+	var positions = make([]int, 0, vinLen)
+	var alphabets = make([]*stringCodec, 0, vinLen)
+	for i := wmiLen; i < vinLen; i++ {
+		var codec = vinCharCodec
+		switch {
+		case i == vinCheckIdx:
+			continue
+		case i == vinYearIdx && c.opts.KeepModelYear:
+			continue
+		case i == vinYearIdx:
+			if strings.IndexRune(vinYearAlphabet, runes[i]) < 0 {
+				return "", ErrInvalidVIN
+			}
+			codec = vinYearCodec
+		case i >= vinSerialIdx && runes[i] >= '0' && runes[i] <= '9':
+			codec = digitCodec
+		case i >= vinSerialIdx:
+			codec = vinLetterCodec
+		}
+		positions = append(positions, i)
+		alphabets = append(alphabets, codec)
+	}
+
+	if err := cryptMixedRadix(newMode, runes, positions, alphabets); err != nil {
+		return "", err
+	}
+
+	// Compute ciphertext check digit
+	var check, err = VINCheck.Compute(string(runes[:vinCheckIdx]) + string(runes[vinCheckIdx+1:]))
+	if err != nil {
+		return "", err
+	}
+	runes[vinCheckIdx] = rune(check[0])
+
+	return string(runes), nil
+}
@@ -0,0 +1,95 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+)
+
+var vins = []string{
+	"1M8GDM9AXKP042788",
+	"1HGCM82633A004352",
+	"11111111111111111",
+	"WVWZZZ1J0XW000001",
+	"5YJ3E1EA2KF317000",
+}
+
+func TestVINCheck(t *testing.T) {
+	for _, vin := range vins {
+		if !VINCheck.Validate(vin) {
+			t.Errorf("%s: %s should be valid", t.Name(), vin)
+		}
+	}
+	if VINCheck.Validate("1M8GDM9A1KP042788") {
+		t.Errorf("%s: %s should be invalid", t.Name(), "1M8GDM9A1KP042788")
+	}
+	if _, err := VINCheck.Compute("1M8GDM9AKP04278"); err == nil {
+		t.Errorf("%s: payload of 15 characters should be rejected", t.Name())
+	}
+}
+
+func TestVINEncryptDecrypt(t *testing.T) {
+	for _, a := range configAlgorithms {
+		for _, keep := range []bool{false, true} {
+			var p, err = NewFpeProcessor(Config{Key: commonKey128, Algorithm: a.alg, Tweak: a.tweak, Field: VINField, VIN: VINOptions{keep}})
+			if err != nil {
+				t.Fatalf("%s(%s): %s", t.Name(), a.alg, err)
+			}
+
+			for _, vin := range vins {
+				var enc, errEnc = p.Encrypt(vin)
+				if errEnc != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, vin, errEnc)
+					continue
+				}
+
+				// Valid VIN with the WMI, model year and serial classes of the plaintext
+				if len(enc) != vinLen || !VINCheck.Validate(enc) || strings.ContainsAny(enc, "IOQ") || enc[:wmiLen] != vin[:wmiLen] {
+					t.Errorf("%s(%s): %s is not a valid VIN with the WMI of %s", t.Name(), a.alg, enc, vin)
+					continue
+				}
+				if strings.IndexByte(vinYearAlphabet, enc[vinYearIdx]) < 0 || (keep && enc[vinYearIdx] != vin[vinYearIdx]) {
+					t.Errorf("%s(%s): %s does not have a valid model year for %s", t.Name(), a.alg, enc, vin)
+				}
+				for i := vinSerialIdx; i < vinLen; i++ {
+					if (charClassOf(rune(enc[i])) == digitCodec) != (charClassOf(rune(vin[i])) == digitCodec) {
+						t.Errorf("%s(%s): %s does not keep the serial number format of %s", t.Name(), a.alg, enc, vin)
+						break
+					}
+				}
+
+				var dec, errDec = p.Decrypt(enc)
+				if errDec != nil {
+					t.Errorf("%s(%s, %s): %s", t.Name(), a.alg, vin, errDec)
+					continue
+				}
+				if strings.Compare(dec, vin) != 0 {
+					t.Errorf("%s(%s):\nhave %s\nwant %s", t.Name(), a.alg, dec, vin)
+				}
+			}
+		}
+	}
+}
+
+func TestVINErrors(t *testing.T) {
+	var p, err = NewFpeProcessor(Config{Key: commonKey128, Field: VINField})
+	if err != nil {
+		t.Fatalf("%s: %s", t.Name(), err)
+	}
+
+	var errorTests = []struct {
+		vin string
+		err error
+	}{
+		{"", ErrInvalidVIN},
+		{"1M8GDM9AXKP04278", ErrInvalidVIN},
+		{"1M8GDM9AXKP0427888", ErrInvalidVIN},
+		{"1m8gdm9axkp042788", ErrInvalidVIN},
+		{"1M8GDM9AXKP04278O", ErrInvalidVIN},
+		{"1M8GDM9A1KP042788", ErrInvalidCheckDigit},
+	}
+	for _, test := range errorTests {
+		if _, errEnc := p.Encrypt(test.vin); errEnc != test.err {
+			t.Errorf("%s(%s):\nhave %v\nwant %v", t.Name(), test.vin, errEnc, test.err)
+		}
+	}
+}